@@ -0,0 +1,87 @@
+package rrule
+
+import "time"
+
+// AdjustToWeekday moves each occurrence to a nearby wd, for schedules that
+// RRULE can't express directly, such as "the Monday before the 15th". A
+// negative direction moves to the nth wd strictly before the occurrence, and
+// a positive one to the nth wd strictly after, where n is the magnitude of
+// direction. A direction of 0 keeps occurrences on wd and moves others to the
+// nearest wd. The time of day is kept.
+//
+// For example, US federal elections are on the first Tuesday after the first
+// Monday of November: the instances of FREQ=MONTHLY;BYMONTH=11;BYDAY=1MO,
+// adjusted to time.Tuesday with a direction of 1.
+func AdjustToWeekday(occurrences []time.Time, wd time.Weekday, direction int) []time.Time {
+	adjusted := make([]time.Time, len(occurrences))
+	for i, t := range occurrences {
+		switch {
+		case direction < 0:
+			t = backToWeekday(t.AddDate(0, 0, -1), wd).AddDate(0, 0, 7*(direction+1))
+		case direction > 0:
+			t = forwardToWeekday(t.AddDate(0, 0, 1), wd).AddDate(0, 0, 7*(direction-1))
+		default:
+			if back := daysFrom(t.Weekday(), wd); back <= 3 {
+				t = t.AddDate(0, 0, -back)
+			} else {
+				t = t.AddDate(0, 0, 7-back)
+			}
+		}
+		adjusted[i] = t
+	}
+	return adjusted
+}
+
+// WeekendShiftPolicy determines where an instance falling on a weekend is
+// observed.
+type WeekendShiftPolicy int
+
+const (
+	// NoWeekendShift leaves weekend instances as they are.
+	NoWeekendShift WeekendShiftPolicy = iota
+
+	// NearestWeekday observes Saturdays on the Friday before and Sundays on
+	// the Monday after, as US federal holidays are.
+	NearestWeekday
+
+	// FollowingMonday observes Saturdays and Sundays on the Monday after.
+	FollowingMonday
+
+	// PrecedingFriday observes Saturdays and Sundays on the Friday before.
+	PrecedingFriday
+)
+
+// AllObserved returns instances of the pattern up to a limited number, like
+// All, but with weekend instances moved to the weekday where they're
+// observed according to policy. It's meant for fixed-date holidays, such as
+// FREQ=YEARLY;BYMONTH=7;BYMONTHDAY=4; with instances less than a few days
+// apart, a moved instance may land on or pass another.
+func (rrule RRule) AllObserved(limit int, policy WeekendShiftPolicy) []time.Time {
+	all := rrule.All(limit)
+	for i, t := range all {
+		all[i] = observedDay(t, policy)
+	}
+	return all
+}
+
+// observedDay returns the day where t is observed according to policy.
+func observedDay(t time.Time, policy WeekendShiftPolicy) time.Time {
+	wd := t.Weekday()
+	if wd != time.Saturday && wd != time.Sunday {
+		return t
+	}
+
+	switch policy {
+	case NearestWeekday:
+		if wd == time.Saturday {
+			return t.AddDate(0, 0, -1)
+		}
+		return t.AddDate(0, 0, 1)
+	case FollowingMonday:
+		return forwardToWeekday(t, time.Monday)
+	case PrecedingFriday:
+		return backToWeekday(t, time.Friday)
+	default:
+		return t
+	}
+}
@@ -0,0 +1,55 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdjustToWeekday(t *testing.T) {
+	// US federal elections: the first Tuesday after the first Monday of November
+	mondays := MustRRule("FREQ=MONTHLY;COUNT=3;BYMONTH=11;BYDAY=1MO")
+	mondays.Dtstart = time.Date(2018, 1, 1, 7, 0, 0, 0, NewYork())
+	elections := AdjustToWeekday(All(mondays.Iterator(), 0), time.Tuesday, 1)
+	assert.Equal(t, []string{"2018-11-06T07:00:00-05:00", "2019-11-05T07:00:00-05:00", "2020-11-03T07:00:00-05:00"}, rfcAll(elections))
+
+	fifteenths := []time.Time{
+		time.Date(2018, 10, 15, 9, 0, 0, 0, time.UTC), // a monday
+		time.Date(2018, 11, 15, 9, 0, 0, 0, time.UTC), // a thursday
+	}
+
+	// the monday before the 15th is never the 15th itself
+	assert.Equal(t, []string{"2018-10-08T09:00:00Z", "2018-11-12T09:00:00Z"}, rfcAll(AdjustToWeekday(fifteenths, time.Monday, -1)))
+	assert.Equal(t, []string{"2018-10-01T09:00:00Z", "2018-11-05T09:00:00Z"}, rfcAll(AdjustToWeekday(fifteenths, time.Monday, -2)))
+	assert.Equal(t, []string{"2018-10-29T09:00:00Z", "2018-11-26T09:00:00Z"}, rfcAll(AdjustToWeekday(fifteenths, time.Monday, 2)))
+
+	// the nearest monday, which may be the day itself
+	assert.Equal(t, []string{"2018-10-15T09:00:00Z", "2018-11-12T09:00:00Z"}, rfcAll(AdjustToWeekday(fifteenths, time.Monday, 0)))
+	assert.Equal(t, []string{"2018-10-12T09:00:00Z", "2018-11-16T09:00:00Z"}, rfcAll(AdjustToWeekday(fifteenths, time.Friday, 0)))
+}
+
+func TestAllObserved(t *testing.T) {
+	// July 4th was a saturday in 2020 and a sunday in 2021
+	july4 := MustRRule("FREQ=YEARLY;COUNT=3;BYMONTH=7;BYMONTHDAY=4")
+	july4.Dtstart = time.Date(2019, 7, 4, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Policy   WeekendShiftPolicy
+		Observed []string
+	}{
+		{NoWeekendShift, []string{"2019-07-04T00:00:00Z", "2020-07-04T00:00:00Z", "2021-07-04T00:00:00Z"}},
+		{NearestWeekday, []string{"2019-07-04T00:00:00Z", "2020-07-03T00:00:00Z", "2021-07-05T00:00:00Z"}},
+		{FollowingMonday, []string{"2019-07-04T00:00:00Z", "2020-07-06T00:00:00Z", "2021-07-05T00:00:00Z"}},
+		{PrecedingFriday, []string{"2019-07-04T00:00:00Z", "2020-07-03T00:00:00Z", "2021-07-02T00:00:00Z"}},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.Observed, rfcAll(july4.AllObserved(0, tc.Policy)))
+	}
+
+	// without a limit, an infinite pattern stops at DefaultHorizon
+	july4 = MustRRule("FREQ=YEARLY;BYMONTH=7;BYMONTHDAY=4")
+	july4.Dtstart = time.Date(2098, 7, 4, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2098-07-04T00:00:00Z", "2099-07-03T00:00:00Z"}, rfcAll(july4.AllObserved(0, NearestWeekday)))
+}
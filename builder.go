@@ -0,0 +1,145 @@
+package rrule
+
+import (
+	"fmt"
+	"time"
+)
+
+// Builder constructs an RRule fluently, as an alternative to filling in its
+// fields. For example,
+//
+//	rrule, err := NewRRule(Weekly).Interval(2).On(time.Tuesday, time.Thursday).Count(10).Starting(dtstart).Build()
+//
+// Each method sets the part of the same name and returns the builder, and
+// Build checks the result.
+type Builder struct {
+	rrule RRule
+	err   error
+}
+
+// NewRRule returns a Builder for a pattern with the given frequency.
+func NewRRule(freq Frequency) *Builder {
+	return &Builder{rrule: RRule{Frequency: freq}}
+}
+
+// Starting sets Dtstart.
+func (b *Builder) Starting(dtstart time.Time) *Builder {
+	b.rrule.Dtstart = dtstart
+	return b
+}
+
+// Interval sets INTERVAL.
+func (b *Builder) Interval(interval int) *Builder {
+	b.rrule.Interval = interval
+	return b
+}
+
+// Count sets COUNT.
+func (b *Builder) Count(count uint64) *Builder {
+	b.rrule.Count = count
+	return b
+}
+
+// Until sets UNTIL.
+func (b *Builder) Until(until time.Time) *Builder {
+	b.rrule.Until = until
+	b.rrule.UntilDate = false
+	return b
+}
+
+// On adds days to BYDAY. Each day is either a time.Weekday, meaning every such
+// day, or a QualifiedWeekday, such as the last Friday. Any other value makes
+// Build fail.
+func (b *Builder) On(days ...interface{}) *Builder {
+	for _, day := range days {
+		switch day := day.(type) {
+		case time.Weekday:
+			b.rrule.ByWeekdays = append(b.rrule.ByWeekdays, QualifiedWeekday{WD: day})
+		case QualifiedWeekday:
+			b.rrule.ByWeekdays = append(b.rrule.ByWeekdays, day)
+		default:
+			if b.err == nil {
+				b.err = fmt.Errorf("%v (%T) is not a time.Weekday or QualifiedWeekday", day, day)
+			}
+		}
+	}
+	return b
+}
+
+// EveryWeekday adds Monday through Friday to BYDAY.
+func (b *Builder) EveryWeekday() *Builder {
+	return b.On(time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+}
+
+// EveryWeekendDay adds Saturday and Sunday to BYDAY.
+func (b *Builder) EveryWeekendDay() *Builder {
+	return b.On(time.Saturday, time.Sunday)
+}
+
+// InMonths adds months to BYMONTH.
+func (b *Builder) InMonths(months ...time.Month) *Builder {
+	b.rrule.ByMonths = append(b.rrule.ByMonths, months...)
+	return b
+}
+
+// OnMonthDays adds days to BYMONTHDAY.
+func (b *Builder) OnMonthDays(days ...int) *Builder {
+	b.rrule.ByMonthDays = append(b.rrule.ByMonthDays, days...)
+	return b
+}
+
+// OnYearDays adds days to BYYEARDAY.
+func (b *Builder) OnYearDays(days ...int) *Builder {
+	b.rrule.ByYearDays = append(b.rrule.ByYearDays, days...)
+	return b
+}
+
+// InWeeks adds weeks to BYWEEKNO.
+func (b *Builder) InWeeks(weeks ...int) *Builder {
+	b.rrule.ByWeekNumbers = append(b.rrule.ByWeekNumbers, weeks...)
+	return b
+}
+
+// AtHours adds hours to BYHOUR.
+func (b *Builder) AtHours(hours ...int) *Builder {
+	b.rrule.ByHours = append(b.rrule.ByHours, hours...)
+	return b
+}
+
+// AtMinutes adds minutes to BYMINUTE.
+func (b *Builder) AtMinutes(minutes ...int) *Builder {
+	b.rrule.ByMinutes = append(b.rrule.ByMinutes, minutes...)
+	return b
+}
+
+// AtSeconds adds seconds to BYSECOND.
+func (b *Builder) AtSeconds(seconds ...int) *Builder {
+	b.rrule.BySeconds = append(b.rrule.BySeconds, seconds...)
+	return b
+}
+
+// AtPositions adds positions to BYSETPOS.
+func (b *Builder) AtPositions(positions ...int) *Builder {
+	b.rrule.BySetPos = append(b.rrule.BySetPos, positions...)
+	return b
+}
+
+// WeekStart sets WKST.
+func (b *Builder) WeekStart(weekday time.Weekday) *Builder {
+	b.rrule.WeekStart = &weekday
+	return b
+}
+
+// Build returns the pattern, or an error if it isn't valid, as reported by
+// RRule.Validate, or if On was given a value that isn't a day.
+func (b *Builder) Build() (RRule, error) {
+	if b.err != nil {
+		return RRule{}, b.err
+	}
+
+	rrule := b.rrule.Clone()
+	if err := rrule.Validate(); err != nil {
+		return RRule{}, err
+	}
+	return rrule, nil
+}
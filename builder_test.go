@@ -0,0 +1,45 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	rrule, err := NewRRule(Weekly).Interval(2).On(time.Tuesday, time.Thursday).Count(10).Starting(now).Build()
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=10;INTERVAL=2;BYDAY=TU,TH", rrule.String())
+	assert.Equal(t, now, rrule.Dtstart)
+
+	rrule, err = NewRRule(Yearly).InMonths(time.November).On(QualifiedWeekday{N: 4, WD: time.Thursday}).AtHours(12).WeekStart(time.Sunday).Build()
+	require.NoError(t, err)
+	assert.Equal(t, MustRRule("FREQ=YEARLY;BYMONTH=11;BYDAY=4TH;BYHOUR=12;WKST=SU").String(), rrule.String())
+
+	rrule, err = NewRRule(Weekly).EveryWeekday().Build()
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", rrule.String())
+	assert.Equal(t, "every weekday", rrule.Describe())
+
+	rrule, err = NewRRule(Daily).EveryWeekendDay().Count(4).Build()
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY;COUNT=4;BYDAY=SA,SU", rrule.String())
+	assert.Equal(t, "every weekend day, for 4 occurrences", rrule.Describe())
+
+	// a built pattern doesn't change with the builder
+	b := NewRRule(Monthly).OnMonthDays(1)
+	first, err := b.Build()
+	require.NoError(t, err)
+	_, err = b.OnMonthDays(15).Build()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, first.ByMonthDays)
+
+	_, err = NewRRule(Daily).Count(2).Until(now).Build()
+	assert.Error(t, err)
+	_, err = NewRRule(Weekly).On("TU").Build()
+	assert.Error(t, err)
+	_, err = NewRRule(Monthly).OnMonthDays(32).Build()
+	assert.Error(t, err)
+}
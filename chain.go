@@ -0,0 +1,64 @@
+package rrule
+
+import (
+	"sort"
+	"time"
+)
+
+// Segment is one version of a series whose pattern changes over time, such
+// as after a "this and following" edit splits a series. The segment is in
+// effect from From until the From of the next segment.
+type Segment struct {
+	// From is when the segment takes effect. Instances of RRule before From
+	// are not included.
+	From time.Time
+
+	// RRule is the pattern in effect for the segment. If its Dtstart is
+	// zero, From is used.
+	RRule RRule
+}
+
+// AllChained returns the instances of a series made of consecutive segments,
+// up to a limited number, like All. Each segment contributes its instances
+// within [From, next From), so instances switch patterns at the segment
+// boundaries. Segments need not be sorted. If limit is 0, an infinite final
+// segment ends at DefaultHorizon, as with RRule.All.
+func AllChained(segments []Segment, limit int) []time.Time {
+	sorted := make([]Segment, len(segments))
+	copy(sorted, segments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].From.Before(sorted[j].From)
+	})
+
+	var all []time.Time
+	for i, seg := range sorted {
+		rrule := seg.RRule
+		if rrule.Dtstart.IsZero() {
+			rrule.Dtstart = seg.From
+		}
+
+		var end *time.Time
+		if i+1 < len(sorted) {
+			end = &sorted[i+1].From
+		}
+
+		var it Iterator
+		it, limit = rrule.boundedIterator(limit)
+		for {
+			if limit > 0 && len(all) == limit {
+				return all
+			}
+
+			next := it.Next()
+			if next == nil || (end != nil && !next.Before(*end)) {
+				break
+			}
+			if next.Before(seg.From) {
+				continue
+			}
+			all = append(all, *next)
+		}
+	}
+
+	return all
+}
@@ -0,0 +1,39 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllChained(t *testing.T) {
+	start := time.Date(2018, 8, 6, 9, 0, 0, 0, time.UTC) // a monday
+	split := time.Date(2018, 8, 20, 0, 0, 0, 0, time.UTC)
+
+	segments := []Segment{
+		// this and following: moved from mondays to wednesdays at 10am
+		{From: split, RRule: RRule{Frequency: Weekly, Count: 2, Dtstart: time.Date(2018, 8, 22, 10, 0, 0, 0, time.UTC)}},
+		{From: start, RRule: RRule{Frequency: Weekly, Dtstart: start}},
+	}
+
+	assert.Equal(t, []string{
+		"2018-08-06T09:00:00Z",
+		"2018-08-13T09:00:00Z",
+		"2018-08-22T10:00:00Z",
+		"2018-08-29T10:00:00Z",
+	}, rfcAll(AllChained(segments, 0)))
+
+	assert.Equal(t, []string{
+		"2018-08-06T09:00:00Z",
+		"2018-08-13T09:00:00Z",
+		"2018-08-22T10:00:00Z",
+	}, rfcAll(AllChained(segments, 3)))
+
+	// without a limit, an infinite final segment stops at DefaultHorizon
+	yearly := []Segment{
+		{From: start, RRule: RRule{Frequency: Yearly, Count: 1}},
+		{From: time.Date(2098, 7, 4, 9, 0, 0, 0, time.UTC), RRule: RRule{Frequency: Yearly}},
+	}
+	assert.Equal(t, []string{"2018-08-06T09:00:00Z", "2098-07-04T09:00:00Z", "2099-07-04T09:00:00Z"}, rfcAll(AllChained(yearly, 0)))
+}
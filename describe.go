@@ -9,18 +9,22 @@ import (
 
 // Describe returns a rough English description of the recurrence.  This is
 // probably not suitable for truly polished UIs, but may be useful in some
-// circumstances.
+// circumstances. It takes its words from the same phrasebook as Text.
 func (rrule RRule) Describe() string {
+	p := english
 	b := &strings.Builder{}
 
-	b.WriteString("every ")
-	if rrule.Interval > 1 {
-		fmt.Fprintf(b, "%d ", rrule.Interval)
-	}
-
-	b.WriteString(freqStrs[rrule.Frequency])
-	if rrule.Interval > 1 {
-		b.WriteString("s")
+	shortcut := weekdayShortcut(rrule)
+	b.WriteString(p.every + " ")
+	switch {
+	case shortcut == "weekday":
+		b.WriteString(p.weekdayUnit)
+	case shortcut == "weekend day":
+		b.WriteString(p.weekendDayUnit)
+	case rrule.Interval > 1:
+		fmt.Fprintf(b, "%d %s", rrule.Interval, p.units[rrule.Frequency][1])
+	default:
+		b.WriteString(p.units[rrule.Frequency][0])
 	}
 
 	if rrule.Count != 0 {
@@ -31,57 +35,98 @@ func (rrule RRule) Describe() string {
 		fmt.Fprintf(b, ", for %d occurrence%s", rrule.Count, plural)
 	}
 	if rrule.WeekStart != nil {
-		fmt.Fprintf(b, ", with weeks starting on %v", rrule.WeekStart)
+		fmt.Fprintf(b, ", with weeks starting on %s", p.weekdayNames[*rrule.WeekStart])
 	}
 	if !rrule.Until.IsZero() {
-		fmt.Fprintf(b, ", until %v", rrule.Until.Format(time.UnixDate))
+		fmt.Fprintf(b, ", %s %v", p.until, rrule.Until.Format(time.UnixDate))
+	}
+	byMonthDesc(b, p, rrule.ByMonths)
+	byTimeDesc(b, p, rrule.ByMonthDays, "day of the month")
+	byTimeDesc(b, p, rrule.ByYearDays, "day of the year")
+	byTimeDesc(b, p, rrule.ByWeekNumbers, "week of the yar")
+	if shortcut == "" {
+		byWeekday(b, p, rrule.ByWeekdays)
 	}
-	byMonthDesc(b, rrule.ByMonths)
-	byTimeDesc(b, rrule.ByMonthDays, "day of the month")
-	byTimeDesc(b, rrule.ByYearDays, "day of the year")
-	byTimeDesc(b, rrule.ByWeekNumbers, "week of the yar")
-	byWeekday(b, rrule.ByWeekdays)
-	byTimeDesc(b, rrule.ByHours, "hour")
-	byTimeDesc(b, rrule.ByMinutes, "minute")
-	byTimeDesc(b, rrule.BySeconds, "second")
+	byTimeDesc(b, p, rrule.ByHours, "hour")
+	byTimeDesc(b, p, rrule.ByMinutes, "minute")
+	byTimeDesc(b, p, rrule.BySeconds, "second")
 
-	setByPosDesc(b, rrule.BySetPos)
+	setByPosDesc(b, p, rrule.BySetPos)
 
 	return b.String()
 }
 
-var freqStrs = map[Frequency]string{
-	Yearly:   "year",
-	Monthly:  "month",
-	Weekly:   "week",
-	Daily:    "day",
-	Hourly:   "hour",
-	Minutely: "minute",
-	Secondly: "second",
+// weekdayShortcut returns "weekday" or "weekend day" for daily and weekly
+// patterns that occur on exactly those days, which read more naturally than
+// listing each day.
+func weekdayShortcut(rrule RRule) string {
+	if rrule.Interval > 1 || (rrule.Frequency != Daily && rrule.Frequency != Weekly) {
+		return ""
+	}
+
+	switch {
+	case isWeekdaySet(rrule.ByWeekdays, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday):
+		return "weekday"
+	case isWeekdaySet(rrule.ByWeekdays, time.Saturday, time.Sunday):
+		return "weekend day"
+	}
+	return ""
+}
+
+// isWeekdaySet reports whether weekdays, ignoring order and repetition,
+// contains exactly the unqualified days in set.
+func isWeekdaySet(weekdays []QualifiedWeekday, set ...time.Weekday) bool {
+	if len(weekdays) == 0 {
+		return false
+	}
+
+	want := map[time.Weekday]bool{}
+	for _, wd := range set {
+		want[wd] = true
+	}
+
+	got := map[time.Weekday]bool{}
+	for _, wd := range weekdays {
+		if wd.N != 0 || !want[wd.WD] {
+			return false
+		}
+		got[wd.WD] = true
+	}
+
+	return len(got) == len(want)
 }
 
-func byWeekday(w io.Writer, weekdays []QualifiedWeekday) {
+func byWeekday(w io.Writer, p phrasebook, weekdays []QualifiedWeekday) {
 	if len(weekdays) == 0 {
 		return
 	}
+
+	switch {
+	case isWeekdaySet(weekdays, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday):
+		fmt.Fprintf(w, ", %s %s", p.on, p.weekdays)
+		return
+	case isWeekdaySet(weekdays, time.Saturday, time.Sunday):
+		fmt.Fprintf(w, ", %s %s", p.on, p.weekends)
+		return
+	}
 	seen := map[QualifiedWeekday]bool{}
 	strs := []string{}
 	for _, w := range weekdays {
 		if !seen[w] {
 			if w.N == 0 {
-				strs = append(strs, w.WD.String())
+				strs = append(strs, p.weekdayNames[w.WD])
 			} else {
-				strs = append(strs, fmt.Sprintf("the %v %v", ordinalWithLastFrom(w.N), w.WD.String()))
+				strs = append(strs, fmt.Sprintf("the %v %v", ordinalWithLastFrom(w.N), p.weekdayNames[w.WD]))
 			}
 		}
 		seen[w] = true
 	}
 
-	fmt.Fprintf(w, ", on %s", joinConj(strs, ", ", "and"))
+	fmt.Fprintf(w, ", %s %s", p.on, p.list(strs, p.and))
 
 }
 
-func byMonthDesc(w io.Writer, months []time.Month) {
+func byMonthDesc(w io.Writer, p phrasebook, months []time.Month) {
 	if len(months) == 0 {
 		return
 	}
@@ -89,23 +134,23 @@ func byMonthDesc(w io.Writer, months []time.Month) {
 	strs := []string{}
 	for _, m := range months {
 		if !seen[m-1] {
-			strs = append(strs, m.String())
+			strs = append(strs, p.monthNames[m-1])
 		}
 		seen[m-1] = true
 	}
 
-	fmt.Fprintf(w, ", in %s", joinConj(strs, ", ", "and"))
+	fmt.Fprintf(w, ", %s %s", p.in, p.list(strs, p.and))
 }
 
-func byTimeDesc(w io.Writer, ints []int, unit string) {
+func byTimeDesc(w io.Writer, p phrasebook, ints []int, unit string) {
 	if len(ints) == 0 {
 		return
 	}
 
-	fmt.Fprintf(w, ", on the %v %s", ordinalList(ints, ", ", "and"), unit)
+	fmt.Fprintf(w, ", %s the %v %s", p.on, ordinalList(p, ints), unit)
 }
 
-func setByPosDesc(w io.Writer, ints []int) {
+func setByPosDesc(w io.Writer, p phrasebook, ints []int) {
 	pos := []int{}
 	neg := []int{}
 
@@ -118,16 +163,16 @@ func setByPosDesc(w io.Writer, ints []int) {
 	}
 
 	if len(pos) > 0 && len(neg) == 0 {
-		fmt.Fprintf(w, ", including only the %v instances", ordinalList(pos, ", ", "and"))
+		fmt.Fprintf(w, ", including only the %v instances", ordinalList(p, pos))
 		return
 	}
 	if len(neg) > 0 && len(pos) == 0 {
-		fmt.Fprintf(w, ", including only the %v instances from the end", ordinalList(neg, ", ", "and"))
+		fmt.Fprintf(w, ", including only the %v instances from the end", ordinalList(p, neg))
 		return
 	}
 
 	if len(neg) > 0 && len(pos) > 0 {
-		fmt.Fprintf(w, ", including only the %v instances and the %v instances from the end", ordinalList(pos, ", ", "and"), ordinalList(neg, ", ", "and"))
+		fmt.Fprintf(w, ", including only the %v instances and the %v instances from the end", ordinalList(p, pos), ordinalList(p, neg))
 		return
 	}
 }
@@ -149,23 +194,30 @@ func joinConj(strs []string, sep, listConj string) string {
 
 }
 
-func ordinalList(ints []int, sep, listConj string) string {
+func ordinalList(p phrasebook, ints []int) string {
 	s := make([]string, len(ints))
 	for i, x := range ints {
 		s[i] = ordinal(x)
 	}
 
-	return joinConj(s, sep, listConj)
+	return p.list(s, p.and)
 }
 
 func ordinal(i int) string {
+	abs := i
+	if abs < 0 {
+		abs = -abs
+	}
+
 	suffix := "th"
-	switch i % 10 {
-	case 1:
+	switch {
+	case abs%100 >= 11 && abs%100 <= 13:
+		// 11th, 12th, 13th
+	case abs%10 == 1:
 		suffix = "st"
-	case 2:
+	case abs%10 == 2:
 		suffix = "nd"
-	case 3:
+	case abs%10 == 3:
 		suffix = "rd"
 	}
 
@@ -0,0 +1,29 @@
+package rrule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	cases := []struct {
+		RRule    string
+		Describe string
+	}{
+		{"FREQ=DAILY;COUNT=3", "every day, for 3 occurrences"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH", "every 2 weeks, on Tuesday and Thursday"},
+		{"FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", "every weekday"},
+		{"FREQ=DAILY;BYDAY=FR,TU,WE,MO,TH", "every weekday"},
+		{"FREQ=WEEKLY;BYDAY=SA,SU", "every weekend day"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=SA,SU", "every 2 weeks, on weekends"},
+		{"FREQ=YEARLY;BYMONTH=1,3;BYMONTHDAY=1", "every year, in January and March, on the 1st day of the month"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,MO;WKST=SU", "every 2 weeks, with weeks starting on Sunday, on Sunday and Monday"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.RRule, func(t *testing.T) {
+			assert.Equal(t, tc.Describe, MustRRule(tc.RRule).Describe())
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package rrule
+
+import "fmt"
+
+// UnsupportedPartError is returned when an RRULE contains a part that this
+// package does not recognize, such as a vendor extension. A lenient Parser
+// skips these parts and records them instead of failing.
+type UnsupportedPartError struct {
+	Part string
+}
+
+func (e *UnsupportedPartError) Error() string {
+	return fmt.Sprintf("%q is not a supported RRULE part", e.Part)
+}
+
+// ParseError describes where parsing failed. Within an RRULE, it identifies
+// the part that failed, such as COUNT; otherwise it identifies the property
+// of the content line, such as DTSTART. Err holds the underlying error, such
+// as an *UnsupportedPartError.
+type ParseError struct {
+	// Line is the 1-based line of the recurrence where the error occurred,
+	// counting folded lines, or 0 when parsing a single RRULE.
+	Line int
+
+	// Property is the RRULE part or the content line property that failed,
+	// and Value its raw value. Both are empty for a line that couldn't be
+	// split into a property and value at all.
+	Property string
+	Value    string
+
+	// Offset is the byte offset of Property from the start of the RRULE, or of
+	// the content line when Line is set. Offsets are counted after unfolding.
+	Offset int
+
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	msg := e.Err.Error()
+	if e.Property != "" {
+		msg = fmt.Sprintf("%s at offset %d: %s", e.Property, e.Offset, msg)
+	}
+	if e.Line > 0 {
+		msg = fmt.Sprintf("line %d: %s", e.Line, msg)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -67,25 +67,56 @@ func expandByWeekdays(tt []time.Time, weekStart time.Weekday, weekdays ...Qualif
 		return tt
 	}
 
+	// offsets of each weekday from the start of the week, in order, so the
+	// expansion is produced already sorted.
+	offsets := make([]int, len(weekdays))
+	for i, wd := range weekdays {
+		offsets[i] = daysTil(weekStart, wd.WD)
+	}
+	sort.Ints(offsets)
+
 	e := make([]time.Time, 0, len(tt)*len(weekdays))
-	for _, t := range tt {
-		t = backToWeekday(t, weekStart)
-		for _, wd := range weekdays {
-			e = append(e, forwardToWeekday(t, wd.WD))
+	for _, offset := range offsets {
+		for _, t := range tt {
+			e = append(e, backToWeekday(t, weekStart).AddDate(0, 0, offset))
 		}
 	}
 
 	return e
 }
-func expandByMonthDays(tt []time.Time, monthdays ...int) []time.Time {
+
+func expandByMonthDays(tt []time.Time, ib InvalidBehavior, monthdays ...int) []time.Time {
 	if len(monthdays) == 0 {
 		return tt
 	}
 
 	e := make([]time.Time, 0, len(tt)*len(monthdays))
 	for _, t := range tt {
+		days := daysInMonth(t)
+
 		for _, md := range monthdays {
-			e = append(e, time.Date(t.Year(), t.Month(), md, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()))
+			// negative days count back from the end of the month
+			if md < 0 {
+				md += days + 1
+			}
+
+			// days past the end of a short month don't exist in it
+			month := t.Month()
+			switch {
+			case md >= 1 && md <= days:
+			case ib == PrevInvalid && md > days:
+				md = days
+			case ib == PrevInvalid:
+				md = 0 // the last day of the previous month
+			case ib == NextInvalid && md > days:
+				month, md = month+1, 1
+			case ib == NextInvalid:
+				md = 1
+			default:
+				continue
+			}
+
+			e = append(e, time.Date(t.Year(), month, md, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()))
 		}
 	}
 
@@ -100,34 +131,115 @@ func expandByYearDays(tt []time.Time, yeardays ...int) []time.Time {
 	e := make([]time.Time, 0, len(tt)*len(yeardays))
 	for _, t := range tt {
 		yearStart := time.Date(t.Year(), time.January, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+		days := daysInYear(t)
 
+		// negative year days count back from the end of the year, so they're
+		// resolved before sorting
+		resolved := make([]int, 0, len(yeardays))
 		for _, yd := range yeardays {
-			e = append(e, yearStart.AddDate(0, 0, yd))
+			if yd < 0 {
+				yd += days + 1
+			}
+
+			// day 366 only exists in leap years
+			if yd < 1 || yd > days {
+				continue
+			}
+
+			resolved = append(resolved, yd)
+		}
+
+		for _, yd := range sortedInts(resolved) {
+			e = append(e, yearStart.AddDate(0, 0, yd-1))
 		}
 	}
 
 	return e
 }
 
+// expandByWeekNumbers expands each time to every day of the given weeks of
+// its year. Week 1 is the first week, starting on weekStarts, with at least
+// four days in the year, so it may begin in late December of the prior year.
+// Negative week numbers count back from the last week of the year.
 func expandByWeekNumbers(tt []time.Time, weekStarts time.Weekday, weekNumbers ...int) []time.Time {
 	if len(weekNumbers) == 0 {
 		return tt
 	}
 
-	e := make([]time.Time, 0, len(tt)*len(weekNumbers))
+	e := make([]time.Time, 0, len(tt)*len(weekNumbers)*7)
 	for _, t := range tt {
-		yearStart := time.Date(t.Year(), time.January, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
-		yearStart = forwardToWeekday(yearStart, t.Weekday())
+		first := firstWeekOfYear(t, t.Year(), weekStarts)
+		weeks := daysBetween(first, firstWeekOfYear(t, t.Year()+1, weekStarts)) / 7
 
 		for _, w := range weekNumbers {
-			e = append(e, yearStart.AddDate(0, 0, (w-1)*7))
+			if w < 0 {
+				w += weeks + 1
+			}
+
+			// week 53 only exists in some years
+			if w < 1 || w > weeks {
+				continue
+			}
+
+			weekStart := first.AddDate(0, 0, (w-1)*7)
+			for d := 0; d < 7; d++ {
+				e = append(e, weekStart.AddDate(0, 0, d))
+			}
 		}
 	}
 
 	return e
 }
 
-func expandByMonths(tt []time.Time, ib invalidBehavior, months ...time.Month) []time.Time {
+// firstWeekOfYear returns the first day of week 1 of year, at the clock time
+// of t.
+func firstWeekOfYear(t time.Time, year int, weekStarts time.Weekday) time.Time {
+	jan1 := time.Date(year, time.January, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	first := backToWeekday(jan1, weekStarts)
+
+	// the week containing January 1st is week 1 only if at least four of its
+	// days are in the new year.
+	if 7-daysFrom(jan1.Weekday(), weekStarts) < 4 {
+		first = first.AddDate(0, 0, 7)
+	}
+
+	return first
+}
+
+// weekNumber returns the number of the week containing t, numbered as by
+// expandByWeekNumbers, along with the number of weeks in the year the week
+// belongs to. Days in late December or early January may belong to a week of
+// the following or prior year.
+func weekNumber(t time.Time, weekStarts time.Weekday) (week, weeks int) {
+	ws := backToWeekday(t, weekStarts)
+
+	// a week belongs to the year holding most of its days, and so its fourth
+	year := ws.AddDate(0, 0, 3).Year()
+	first := firstWeekOfYear(t, year, weekStarts)
+
+	week = daysBetween(first, ws)/7 + 1
+	weeks = daysBetween(first, firstWeekOfYear(t, year+1, weekStarts)) / 7
+	return week, weeks
+}
+
+// daysInMonth returns the number of days in the month of t.
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// daysInYear returns the number of days in the year of t.
+func daysInYear(t time.Time) int {
+	return time.Date(t.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+// daysBetween returns the number of calendar days from a to b.
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+func expandByMonths(tt []time.Time, ib InvalidBehavior, months ...time.Month) []time.Time {
 	if len(months) == 0 {
 		return tt
 	}
@@ -138,13 +250,13 @@ func expandByMonths(tt []time.Time, ib invalidBehavior, months ...time.Month) []
 			set := time.Date(t.Year(), m, t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
 			if set.Month() != m {
 				switch ib {
-				case prevInvalid:
-					set = time.Date(t.Year(), t.Month()+1, -1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+				case PrevInvalid:
+					set = time.Date(t.Year(), m+1, 0, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
 					e = append(e, set)
-				case nextInvalid:
-					set = time.Date(t.Year(), t.Month()+1, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+				case NextInvalid:
+					set = time.Date(t.Year(), m+1, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
 					e = append(e, set)
-				case omitInvalid:
+				case OmitInvalid:
 					// do nothing
 				}
 			} else {
@@ -156,24 +268,22 @@ func expandByMonths(tt []time.Time, ib invalidBehavior, months ...time.Month) []
 	return e
 }
 
-// expandMonthByWeekdays does a special expansion of the month by weekdays. If
-// bySetPos is not nil, it is assumed tt is the full set of instances within the
-// monthly iteration, and only the instances matching the posisions of bySetPos
-// are returned. This is an optimization.
-func expandMonthByWeekdays(tt []time.Time, ib invalidBehavior, bySetPos []int, weekdays ...QualifiedWeekday) []time.Time {
+// expandMonthByWeekdays expands each time in tt to the days of its month
+// matching weekdays.
+func expandMonthByWeekdays(tt []time.Time, ib InvalidBehavior, weekdays ...QualifiedWeekday) []time.Time {
 	if len(weekdays) == 0 {
 		return tt
 	}
 
 	e := make([]time.Time, 0, len(tt))
 	for _, t := range tt {
-		e = append(e, weekdaysInMonth(t, weekdays, bySetPos, ib)...)
+		e = append(e, weekdaysInMonth(t, weekdays, ib)...)
 	}
 
 	return e
 }
 
-func expandYearByWeekdays(tt []time.Time, ib invalidBehavior, weekdays ...QualifiedWeekday) []time.Time {
+func expandYearByWeekdays(tt []time.Time, ib InvalidBehavior, weekdays ...QualifiedWeekday) []time.Time {
 	if len(weekdays) == 0 {
 		return tt
 	}
@@ -0,0 +1,17 @@
+package rrule
+
+// GapBehavior decides what happens to an instance whose local time doesn't
+// exist, because it falls in the gap left when clocks are set forward for
+// daylight saving time, such as 2:30 on the day New York skips from 2:00 to
+// 3:00.
+type GapBehavior int
+
+const (
+	// RollForward moves the instance forward by the length of the gap, so
+	// 2:30 becomes 3:30. This is how RFC 5545 interprets a nonexistent local
+	// time: using the UTC offset from before the gap.
+	RollForward GapBehavior = iota
+
+	// SkipGap omits the instance.
+	SkipGap
+)
@@ -45,9 +45,9 @@ func (gi *groupIterator) Peek() *time.Time {
 				if t.Before(*min) {
 					min = t
 					minIdx = i
-				} else if t.Truncate(time.Second).Equal(min.Truncate(time.Second)) {
+				} else if t.Equal(*min) {
 					// we equal the current minimum. we can safely
-					// skip this
+					// skip this, but only at exactly the same instant
 					iter.Next()
 				}
 			}
@@ -0,0 +1,79 @@
+package rrule
+
+import "time"
+
+// InferRRule attempts to find a simple pattern, with only a frequency,
+// interval, and count, that produces exactly the given sorted dates. This can
+// compress a list of RDATEs into an RRULE. The coarsest frequency that fits is
+// preferred, so dates a week apart infer WEEKLY rather than DAILY;INTERVAL=7.
+// The returned pattern's Dtstart is the first date.
+//
+// InferRRule is conservative: a pattern is only returned if it reproduces
+// the dates exactly, otherwise the boolean is false. At least two dates are
+// required.
+func InferRRule(dates []time.Time) (RRule, bool) {
+	if len(dates) < 2 {
+		return RRule{}, false
+	}
+
+	first, second := dates[0], dates[1]
+	if !second.After(first) {
+		return RRule{}, false
+	}
+
+	months := (second.Year()-first.Year())*12 + int(second.Month()-first.Month())
+	days := int(civilDate(second).Sub(civilDate(first)) / (24 * time.Hour))
+	elapsed := second.Sub(first)
+
+	candidates := []struct {
+		freq     Frequency
+		interval int
+	}{
+		{Yearly, months / 12},
+		{Monthly, months},
+		{Weekly, days / 7},
+		{Daily, days},
+		{Hourly, int(elapsed / time.Hour)},
+		{Minutely, int(elapsed / time.Minute)},
+		{Secondly, int(elapsed / time.Second)},
+	}
+
+	for _, c := range candidates {
+		if c.interval <= 0 {
+			continue
+		}
+
+		rrule := RRule{
+			Frequency: c.freq,
+			Count:     uint64(len(dates)),
+			Dtstart:   first,
+		}
+		if c.interval > 1 {
+			rrule.Interval = c.interval
+		}
+
+		if sameTimes(All(rrule.Iterator(), 0), dates) {
+			return rrule, true
+		}
+	}
+
+	return RRule{}, false
+}
+
+// civilDate returns the calendar date of t as midnight UTC, so differences
+// between dates count whole days regardless of daylight savings.
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func sameTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
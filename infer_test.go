@@ -0,0 +1,65 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferRRule(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Dates  []time.Time
+		String string
+	}{
+		{
+			Name:   "weekly",
+			Dates:  []time.Time{time.Date(2018, 8, 25, 9, 0, 0, 0, time.UTC), time.Date(2018, 9, 1, 9, 0, 0, 0, time.UTC), time.Date(2018, 9, 8, 9, 0, 0, 0, time.UTC)},
+			String: "FREQ=WEEKLY;COUNT=3",
+		},
+		{
+			Name:   "every other day",
+			Dates:  []time.Time{time.Date(2018, 8, 25, 9, 0, 0, 0, time.UTC), time.Date(2018, 8, 27, 9, 0, 0, 0, time.UTC), time.Date(2018, 8, 29, 9, 0, 0, 0, time.UTC)},
+			String: "FREQ=DAILY;COUNT=3;INTERVAL=2",
+		},
+		{
+			Name:   "quarterly",
+			Dates:  []time.Time{time.Date(2018, 1, 15, 9, 0, 0, 0, time.UTC), time.Date(2018, 4, 15, 9, 0, 0, 0, time.UTC), time.Date(2018, 7, 15, 9, 0, 0, 0, time.UTC)},
+			String: "FREQ=MONTHLY;COUNT=3;INTERVAL=3",
+		},
+		{
+			Name:   "daily across daylight savings",
+			Dates:  []time.Time{time.Date(2018, 11, 3, 9, 0, 0, 0, NewYork()), time.Date(2018, 11, 4, 9, 0, 0, 0, NewYork()), time.Date(2018, 11, 5, 9, 0, 0, 0, NewYork())},
+			String: "FREQ=DAILY;COUNT=3",
+		},
+		{
+			Name:   "every 90 minutes",
+			Dates:  []time.Time{time.Date(2018, 8, 25, 23, 0, 0, 0, time.UTC), time.Date(2018, 8, 26, 0, 30, 0, 0, time.UTC), time.Date(2018, 8, 26, 2, 0, 0, 0, time.UTC)},
+			String: "FREQ=MINUTELY;COUNT=3;INTERVAL=90",
+		},
+		{
+			Name:  "irregular",
+			Dates: []time.Time{time.Date(2018, 8, 25, 9, 0, 0, 0, time.UTC), time.Date(2018, 8, 26, 9, 0, 0, 0, time.UTC), time.Date(2018, 8, 28, 9, 0, 0, 0, time.UTC)},
+		},
+		{
+			Name:  "single",
+			Dates: []time.Time{time.Date(2018, 8, 25, 9, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			rrule, ok := InferRRule(tc.Dates)
+			if tc.String == "" {
+				assert.False(t, ok)
+				return
+			}
+
+			require.True(t, ok)
+			assert.Equal(t, tc.String, rrule.String())
+			assert.True(t, tc.Dates[0].Equal(rrule.Dtstart))
+		})
+	}
+}
@@ -1,9 +1,20 @@
 package rrule
 
-type invalidBehavior int
+// InvalidBehavior decides what happens to an instance whose day doesn't exist,
+// such as the 31st of a month when BYMONTHDAY=31 meets April, or February 29th
+// in a common year.
+type InvalidBehavior int
 
 const (
-	omitInvalid invalidBehavior = iota
-	nextInvalid
-	prevInvalid
+	// OmitInvalid skips the instance. This is the default, and how RFC 5545
+	// treats days that don't exist.
+	OmitInvalid InvalidBehavior = iota
+
+	// NextInvalid moves the instance to the first day after the gap, such as
+	// March 1st for February 30th.
+	NextInvalid
+
+	// PrevInvalid moves the instance to the last day before the gap, such as
+	// February 28th for February 30th.
+	PrevInvalid
 )
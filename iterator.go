@@ -1,10 +1,14 @@
 package rrule
 
 import (
+	"context"
+	"sort"
 	"time"
 )
 
-// Iterator scans over a series of times.
+// Iterator scans over a series of times in chronological order. Times are
+// generated lazily, one period of the pattern at a time, so an Iterator can be
+// used to take just the first few instances of an infinite pattern.
 type Iterator interface {
 	// Peek returns the next time without advancing the iterator, or nil if
 	// the iterator has ended.
@@ -27,13 +31,36 @@ type iterator struct {
 	next func() *time.Time
 
 	// variations returns all the possible variations
-	// of the key time t
+	// of the key time t. If nil, the pattern is simple,
+	// and every key time is itself an instance.
 	variations func(t *time.Time) []time.Time
 
 	// valid determines if a particular key time is a valid recurrence.
+	// It is unused when variations is nil.
 	valid func(t *time.Time) bool
 
 	setpos []int
+
+	// last is the latest instance queued so far.
+	last time.Time
+
+	// loc is set when key times and their variations are floating, as for
+	// frequencies of DAILY and up, and each is moved into loc before it's
+	// compared with minTime and maxTime. gap decides what happens to those
+	// that don't exist in loc.
+	loc *time.Location
+	gap GapBehavior
+
+	// ctx, if set, ends the iterator early once it's done, recording why in
+	// err. It's checked every contextPollInterval key times, since checking is
+	// comparatively slow.
+	ctx  context.Context
+	keys int
+	err  error
+
+	// single backs the queue for simple patterns, avoiding an allocation
+	// per instance.
+	single [1]time.Time
 }
 
 func (i *iterator) Next() *time.Time {
@@ -58,7 +85,7 @@ func (i *iterator) Peek() *time.Time {
 		}
 	}
 
-	if i.next == nil {
+	if i.next == nil || i.err != nil {
 		return nil
 	}
 
@@ -67,22 +94,74 @@ func (i *iterator) Peek() *time.Time {
 			return nil
 		}
 
+		if i.ctx != nil {
+			if i.keys%contextPollInterval == 0 {
+				if i.err = i.ctx.Err(); i.err != nil {
+					return nil
+				}
+			}
+			i.keys++
+		}
+
 		key := i.next()
 		if key == nil {
 			return nil
 		}
 
+		if i.variations == nil {
+			// simple patterns: the key time is the only variation
+			if i.loc != nil {
+				t, ok := unfloat(*key, i.loc, i.gap)
+				if !ok {
+					continue
+				}
+				key = &t
+			}
+			if key.After(i.maxTime) {
+				i.pastMaxTime = true
+				return nil
+			}
+			if key.Before(i.minTime) {
+				continue
+			}
+
+			i.totalQueued++
+			i.single[0] = *key
+			i.queue = i.single[:]
+			return key
+		}
+
 		if !i.valid(key) {
 			continue
 		}
 
 		variations := i.variations(key)
 
-		// remove any variations before the min time
-		for len(variations) > 0 && variations[0].Before(i.minTime) {
-			variations = variations[1:]
+		if i.loc != nil {
+			unfloated := variations[:0]
+			for _, v := range variations {
+				if t, ok := unfloat(v, i.loc, i.gap); ok {
+					unfloated = append(unfloated, t)
+				}
+			}
+			variations = unfloated
 		}
 
+		variations = uniqueTimes(variations)
+
+		// remove any variations before the min time. variations within the
+		// first period may straddle it, e.g. a weekly rule whose BYDAY
+		// includes days earlier in the week than the start. likewise remove
+		// any already returned for an earlier key time, as BYWEEKNO can
+		// produce days of a neighboring year.
+		kept := variations[:0]
+		for _, v := range variations {
+			if !v.Before(i.minTime) && (i.last.IsZero() || v.After(i.last)) {
+				kept = append(kept, v)
+			}
+		}
+		variations = kept
+
 		// remove any variations after the max time
 		if !i.maxTime.IsZero() {
 			for idx, v := range variations {
@@ -106,11 +185,70 @@ func (i *iterator) Peek() *time.Time {
 		}
 
 		i.totalQueued += uint64(len(variations))
+		i.last = variations[len(variations)-1]
 
 		i.queue = variations[:]
 		return &variations[0]
 	}
 }
 
+// uniqueTimes sorts tt in place and removes duplicate instants, which
+// overlapping BYxxx parts can produce, such as BYMONTHDAY=1 with BYYEARDAY=1.
+func uniqueTimes(tt []time.Time) []time.Time {
+	sorted := true
+	for idx := 1; idx < len(tt); idx++ {
+		if !tt[idx-1].Before(tt[idx]) {
+			sorted = false
+			break
+		}
+	}
+	if sorted {
+		return tt
+	}
+
+	sort.Slice(tt, func(a, b int) bool { return tt[a].Before(tt[b]) })
+	unique := tt[:1]
+	for _, t := range tt[1:] {
+		if !t.Equal(unique[len(unique)-1]) {
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
+
+// contextPollInterval is how many key times an iterator with a context
+// generates between checks of whether the context is done.
+const contextPollInterval = 256
+
 // https://stackoverflow.com/questions/25065055/what-is-the-maximum-time-time-in-go
 var absoluteMaxTime = time.Date(219248499, 01, 01, 0, 0, 0, 0, time.UTC)
+
+// InLocation returns an Iterator over the same instants as it, with each
+// time in loc, such as a viewer's timezone. The instances are still computed
+// in the location of the pattern, so daily instances in New York stay at the
+// same wall clock time there, while their time in loc follows both
+// locations' daylight savings transitions.
+func InLocation(it Iterator, loc *time.Location) Iterator {
+	return &locationIterator{it: it, loc: loc}
+}
+
+type locationIterator struct {
+	it  Iterator
+	loc *time.Location
+}
+
+func (li *locationIterator) Peek() *time.Time {
+	return li.in(li.it.Peek())
+}
+
+func (li *locationIterator) Next() *time.Time {
+	return li.in(li.it.Next())
+}
+
+func (li *locationIterator) in(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	in := t.In(li.loc)
+	return &in
+}
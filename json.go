@@ -0,0 +1,124 @@
+package rrule
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// recurrenceJSON is the JSON encoding of a Recurrence. Times are encoded in
+// RFC 3339, which keeps only their offset, so the name of the location of
+// Dtstart is kept alongside them.
+type recurrenceJSON struct {
+	Dtstart          time.Time   `json:"dtstart"`
+	TZID             string      `json:"tzid,omitempty"`
+	FloatingLocation bool        `json:"floating,omitempty"`
+	DateValued       bool        `json:"dateValued,omitempty"`
+	RRules           []RRule     `json:"rrules,omitempty"`
+	ExRules          []RRule     `json:"exrules,omitempty"`
+	RDates           []time.Time `json:"rdates,omitempty"`
+	RPeriods         []Period    `json:"rperiods,omitempty"`
+	ExDates          []time.Time `json:"exdates,omitempty"`
+	ExDays           []time.Time `json:"exdays,omitempty"`
+	Duration         string      `json:"duration,omitempty"`
+	DurationDays     int         `json:"durationDays,omitempty"`
+	Dtend            *time.Time  `json:"dtend,omitempty"`
+	Extras           []Property  `json:"extras,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. The recurrence is encoded as an
+// object with a field for each of its components; patterns are encoded as
+// their String, and times in RFC 3339 along with the name of the location of
+// Dtstart, so that a recurrence decoded by UnmarshalJSON is evaluated in the
+// same location, whether or not it's floating. Raw is not encoded.
+func (r Recurrence) MarshalJSON() ([]byte, error) {
+	enc := recurrenceJSON{
+		Dtstart:          r.Dtstart,
+		FloatingLocation: r.FloatingLocation,
+		DateValued:       r.DateValued,
+		RRules:           r.RRules,
+		ExRules:          r.ExRules,
+		RDates:           r.RDates,
+		RPeriods:         r.RPeriods,
+		ExDates:          r.ExDates,
+		ExDays:           r.ExDays,
+		DurationDays:     r.DurationDays,
+		Extras:           r.Extras,
+	}
+
+	// fixed zones are fully described by the offset of each time
+	if name := r.Dtstart.Location().String(); name != "" {
+		if _, err := time.LoadLocation(name); err == nil {
+			enc.TZID = name
+		}
+	}
+
+	if r.Duration != 0 {
+		enc.Duration = r.Duration.String()
+	}
+	if !r.Dtend.IsZero() {
+		enc.Dtend = &r.Dtend
+	}
+
+	return json.Marshal(enc)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding the output of
+// MarshalJSON.
+func (r *Recurrence) UnmarshalJSON(b []byte) error {
+	var dec recurrenceJSON
+	if err := json.Unmarshal(b, &dec); err != nil {
+		return err
+	}
+
+	loc := dec.Dtstart.Location()
+	if dec.TZID != "" {
+		var err error
+		loc, err = time.LoadLocation(dec.TZID)
+		if err != nil {
+			return err
+		}
+	}
+
+	var duration time.Duration
+	if dec.Duration != "" {
+		var err error
+		duration, err = time.ParseDuration(dec.Duration)
+		if err != nil {
+			return err
+		}
+	}
+
+	// times written in UTC, such as RDATE:20180901T120000Z, are kept in UTC
+	in := func(tt []time.Time) []time.Time {
+		for i, t := range tt {
+			if t.Location() != time.UTC {
+				tt[i] = t.In(loc)
+			}
+		}
+		return tt
+	}
+	for i, period := range dec.RPeriods {
+		dec.RPeriods[i] = Period{Start: in([]time.Time{period.Start})[0], End: in([]time.Time{period.End})[0]}
+	}
+
+	*r = Recurrence{
+		Dtstart:          dec.Dtstart.In(loc),
+		FloatingLocation: dec.FloatingLocation,
+		DateValued:       dec.DateValued,
+		RRules:           dec.RRules,
+		ExRules:          dec.ExRules,
+		RDates:           in(dec.RDates),
+		RPeriods:         dec.RPeriods,
+		ExDates:          in(dec.ExDates),
+		ExDays:           in(dec.ExDays),
+		Duration:         duration,
+		DurationDays:     dec.DurationDays,
+		Extras:           dec.Extras,
+	}
+	if dec.Dtend != nil {
+		r.Dtend = dec.Dtend.In(loc)
+	}
+	r.setDtstart()
+
+	return nil
+}
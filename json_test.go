@@ -0,0 +1,70 @@
+package rrule
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurrenceJSON(t *testing.T) {
+	zoned, err := ParseRecurrence([]byte("DTSTART;TZID=America/New_York:20180825T090807\nRRULE:FREQ=WEEKLY;COUNT=6;BYDAY=SA,SU;WKST=SU\nRDATE:20180901T120000Z\nEXDATE;TZID=America/New_York:20180826T090807"), time.UTC)
+	require.NoError(t, err)
+	zoned.Duration = 90 * time.Minute
+	zoned.DurationDays = 1
+
+	b, err := json.Marshal(zoned)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"dtstart": "2018-08-25T09:08:07-04:00",
+		"tzid": "America/New_York",
+		"rrules": ["FREQ=WEEKLY;COUNT=6;BYDAY=SA,SU;WKST=SU"],
+		"rdates": ["2018-09-01T12:00:00Z"],
+		"exdates": ["2018-08-26T09:08:07-04:00"],
+		"duration": "1h30m0s",
+		"durationDays": 1
+	}`, string(b))
+
+	var decoded Recurrence
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, zoned.String(), decoded.String())
+	assert.Equal(t, NewYork().String(), decoded.Location().String())
+	assert.Equal(t, zoned.Duration, decoded.Duration)
+	assert.Equal(t, zoned.DurationDays, decoded.DurationDays)
+	assert.Equal(t, rfcAll(utcAll(All(zoned.Iterator(), 0))), rfcAll(utcAll(All(decoded.Iterator(), 0))))
+
+	// a floating recurrence keeps its location rather than being pinned to UTC
+	floating, err := ParseRecurrence([]byte("DTSTART:20180825T090807\nRRULE:FREQ=DAILY;COUNT=3"), Phoenix())
+	require.NoError(t, err)
+
+	b, err = json.Marshal(floating)
+	require.NoError(t, err)
+
+	decoded = Recurrence{}
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.True(t, decoded.FloatingLocation)
+	assert.Equal(t, Phoenix().String(), decoded.Location().String())
+	assert.Equal(t, floating.String(), decoded.String())
+	assert.Equal(t, rfcAll(All(floating.Iterator(), 0)), rfcAll(All(decoded.Iterator(), 0)))
+
+	// a recurrence held by value is encoded the same way
+	type event struct {
+		Recurrence Recurrence `json:"recurrence"`
+	}
+	b, err = json.Marshal(event{Recurrence: *zoned})
+	require.NoError(t, err)
+
+	var decodedEvent event
+	require.NoError(t, json.Unmarshal(b, &decodedEvent))
+	assert.Equal(t, zoned.String(), decodedEvent.Recurrence.String())
+	assert.Equal(t, NewYork().String(), decodedEvent.Recurrence.Location().String())
+
+	b, err = json.Marshal(*zoned)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"tzid":"America/New_York"`)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"dtstart":"2018-08-25T09:08:07Z","tzid":"Nowhere/Special"}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"dtstart":"2018-08-25T09:08:07Z","rrules":["FREQ=NEVER"]}`), &decoded))
+}
@@ -10,6 +10,9 @@ func limitBySetPos(tt []time.Time, setpos []int) []time.Time {
 		return tt
 	}
 
+	// positions count through the set in order, however it was expanded
+	tt = uniqueTimes(tt)
+
 	// a map of tt indexes to include
 	include := map[int]bool{}
 
@@ -25,7 +28,8 @@ func limitBySetPos(tt []time.Time, setpos []int) []time.Time {
 
 	ret := make([]time.Time, 0, len(include))
 	for included := range include {
-		if len(tt) > included {
+		// positions beyond either end of the set select nothing
+		if included >= 0 && included < len(tt) {
 			ret = append(ret, tt[included])
 		}
 	}
@@ -37,32 +41,77 @@ func limitBySetPos(tt []time.Time, setpos []int) []time.Time {
 	return ret
 }
 
-func limitInstancesBySetPos(tt []int, setpos []int) []int {
-	if len(setpos) == 0 {
+// limitByWeekdays removes the times in tt that don't fall on one of weekdays,
+// ignoring the N modifier. It's used where BYDAY limits, rather than expands,
+// the instances of a period.
+func limitByWeekdays(tt []time.Time, weekdays []QualifiedWeekday) []time.Time {
+	if len(weekdays) == 0 {
 		return tt
 	}
 
-	// a map of tt indexes to include
-	include := make(map[int]bool, len(setpos))
+	valid := validWeekday(weekdays)
 
-	for _, sp := range setpos {
-		if sp < 0 {
-			sp = len(tt) + sp
-		} else {
-			sp-- // setpos is 1-indexed in the rrule. adjust here
+	ret := tt[:0]
+	for i := range tt {
+		if valid(&tt[i]) {
+			ret = append(ret, tt[i])
 		}
+	}
 
-		include[sp] = true
+	return ret
+}
+
+// limitByMonths removes the times in tt that don't fall in one of months.
+func limitByMonths(tt []time.Time, months []time.Month) []time.Time {
+	if len(months) == 0 {
+		return tt
 	}
 
-	ret := make([]int, 0, len(include))
-	for included := range include {
-		if len(tt) > included {
-			ret = append(ret, tt[included])
+	valid := validMonth(months)
+
+	ret := tt[:0]
+	for i := range tt {
+		if valid(&tt[i]) {
+			ret = append(ret, tt[i])
+		}
+	}
+
+	return ret
+}
+
+// limitByMonthDays removes the times in tt that don't fall on one of
+// monthdays.
+func limitByMonthDays(tt []time.Time, monthdays []int) []time.Time {
+	if len(monthdays) == 0 {
+		return tt
+	}
+
+	valid := validMonthDay(monthdays)
+
+	ret := tt[:0]
+	for i := range tt {
+		if valid(&tt[i]) {
+			ret = append(ret, tt[i])
 		}
 	}
 
-	sort.Ints(ret)
+	return ret
+}
+
+// limitByYearDays removes the times in tt that don't fall on one of yeardays.
+func limitByYearDays(tt []time.Time, yeardays []int) []time.Time {
+	if len(yeardays) == 0 {
+		return tt
+	}
+
+	valid := validYearDay(yeardays)
+
+	ret := tt[:0]
+	for i := range tt {
+		if valid(&tt[i]) {
+			ret = append(ret, tt[i])
+		}
+	}
 
 	return ret
 }
@@ -0,0 +1,27 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitBySetPos(t *testing.T) {
+	start := time.Date(2018, 8, 25, 10, 0, 0, 0, time.UTC)
+
+	minute := make([]time.Time, 60)
+	for i := range minute {
+		minute[i] = start.Add(time.Duration(i) * time.Second)
+	}
+
+	set := func(setpos ...int) []time.Time {
+		tt := append([]time.Time(nil), minute...)
+		return limitBySetPos(tt, setpos)
+	}
+
+	assert.Equal(t, []time.Time{minute[29], minute[59]}, set(30, -1))
+	assert.Equal(t, []time.Time{minute[0], minute[59]}, set(-60, 60))
+	assert.Equal(t, []time.Time{minute[30]}, set(-30, -30))
+	assert.Empty(t, set(61, -61))
+}
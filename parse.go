@@ -13,87 +13,330 @@ import (
 )
 
 // ParseRecurrence parses a whole recurrence from an iCalendar object. iCalendar
-// properties recognized are DTSTART, RRULE, EXRULE, RDATE, EXDATE. Others are
-// ignored.
+// properties recognized are DTSTART, DTEND, DURATION, RRULE, EXRULE, RDATE,
+// EXDATE. Others are kept in Extras, in the order they appear. RDATE and
+// EXDATE may list several comma-separated values.
 //
 // loc defines what "local" means to the parsed rules. Some patterns may
 // specify a "floating" time, one without a timezone or offset, which matches
 // a different actual time in different timezones. For example,
 //
-//	RRULE:FREQ=YEARLY;BYSECOND=-10
+//	DTSTART:19991231T000000
+//	RRULE:FREQ=YEARLY;BYHOUR=-1;BYMINUTE=-1;BYSECOND=-10
 //
 // might be useful to alert you to start counting down to the new year, no
 // matter your timezone. A rule with a specific timezone, however,
 //
 //	DTSTART;TZID=America/New_York:19991231T000000
-//	RRULE:FREQ=YEARLY;BYSECOND=-10
+//	RRULE:FREQ=YEARLY;BYHOUR=-1;BYMINUTE=-1;BYSECOND=-10
 //
 // would track a specific timezone and ignore loc. This example would alert you
 // to count down to the ball dropping in New York's Times Square for each new year.
 //
 // If nil, time.UTC will be used.
+//
+// Lines may end with CRLF, as in .ics files, or LF, and a leading UTF-8 byte
+// order mark is ignored. Long lines folded onto several physical lines, by a
+// line break followed by a space or tab, are unfolded before parsing. A line
+// that fails to parse produces a *ParseError with its line number.
+//
+// The recurrence is validated as by Recurrence.Validate, except that a
+// pattern whose UNTIL is before DTSTART is accepted, since feeds contain
+// them, and simply produces no instances, as RFC 5545 specifies.
 func ParseRecurrence(src []byte, loc *time.Location) (*Recurrence, error) {
-	scanner := bufio.NewScanner(bytes.NewBuffer(src))
+	return (&Parser{}).ParseRecurrence(src, loc)
+}
+
+// Parser parses recurrences and rules with configurable strictness. The zero
+// value is strict, and its methods then behave exactly like the package-level
+// functions of the same name.
+type Parser struct {
+	// Lenient causes RRULE parts this package does not support to be skipped
+	// rather than failing the parse. Each skipped part is recorded in Skipped.
+	// RDATE and EXDATE values whose value type doesn't match DTSTART are
+	// coerced to match rather than rejected.
+	Lenient bool
+
+	// Skipped holds the unsupported parts encountered by the most recent
+	// lenient parse.
+	Skipped []*UnsupportedPartError
+
+	// WeekdayAliases maps additional, non-standard weekday abbreviations to
+	// weekdays, for ingesting rules that use localized names. Aliases are
+	// matched case-insensitively and take precedence over the RFC 5545
+	// abbreviations. When nil, only the standard abbreviations are accepted.
+	WeekdayAliases map[string]time.Weekday
+}
+
+// ParseRecurrence is like the package-level ParseRecurrence, but uses the
+// configuration of p.
+func (p *Parser) ParseRecurrence(src []byte, loc *time.Location) (*Recurrence, error) {
+	p.Skipped = nil
+
+	src = bytes.TrimPrefix(src, []byte("\xef\xbb\xbf"))
+
+	// bufio.ScanLines also drops the CR of each CRLF
+	scanner := bufio.NewScanner(bytes.NewBuffer(unfold(src)))
+	lines := contentLineStarts(src)
 
 	recurrence := &Recurrence{}
 
-	for scanner.Scan() {
+	// date-valued RDATEs are reconciled with DTSTART's value type once the
+	// whole recurrence has been read.
+	var rdays []time.Time
+
+	for i := 0; scanner.Scan(); i++ {
 		text := scanner.Text()
 		colonIdx := strings.IndexAny(text, ":;")
 
 		if colonIdx < 0 || len(text)-1 == colonIdx {
-			return nil, fmt.Errorf("misformatted line %q", text)
+			return nil, &ParseError{Line: lines[i], Err: fmt.Errorf("misformatted line %q", text)}
 		}
 
 		propName := text[:colonIdx]
 		propVal := text[colonIdx+1:]
 
-		switch propName {
-		case "DTSTART":
-			t, floating, err := parseTime(text, loc)
+		recurrence.Raw = append(recurrence.Raw, text)
+
+		if err := p.parseProperty(recurrence, &rdays, text, propName, propVal, loc); err != nil {
+			var parseErr *ParseError
+			if errors.As(err, &parseErr) {
+				// the offset of an RRULE part is relative to the rule
+				parseErr.Offset += colonIdx + 1
+			} else {
+				parseErr = &ParseError{Property: propName, Value: propertyValue(text), Err: err}
+			}
+			parseErr.Line = lines[i]
+			return nil, parseErr
+		}
+	}
+
+	if err := p.reconcileValueTypes(recurrence, rdays); err != nil {
+		return nil, err
+	}
+
+	recurrence.setDtstart()
+
+	if !recurrence.Dtend.IsZero() {
+		if recurrence.Duration != 0 || recurrence.DurationDays != 0 {
+			return nil, errors.New("DTEND and DURATION must not both be specified")
+		}
+		recurrence.Duration = recurrence.Dtend.Sub(recurrence.Dtstart)
+	}
+
+	if err := recurrence.validate(allowUntilBeforeStart); err != nil {
+		return nil, err
+	}
+
+	return recurrence, nil
+}
+
+// parseProperty parses a single content line of a recurrence into it.
+// Date-valued RDATEs are collected into rdays.
+func (p *Parser) parseProperty(recurrence *Recurrence, rdays *[]time.Time, text, propName, propVal string, loc *time.Location) error {
+	switch propName {
+	case "DTSTART":
+		if propertyParam(text, "VALUE") == "DATE" {
+			t, err := parseDate(propertyValue(text), loc)
 			if err != nil {
-				return nil, err
+				return err
 			}
 			recurrence.Dtstart = t
-			recurrence.FloatingLocation = floating
+			recurrence.FloatingLocation = true
+			recurrence.DateValued = true
+			return nil
+		}
+
+		t, floating, err := parseTime(text, loc)
+		if err != nil {
+			return err
+		}
+		recurrence.Dtstart = t
+		recurrence.FloatingLocation = floating
 
-		case "RRULE":
-			rrule, err := ParseRRule(propVal)
+	case "DTEND":
+		if propertyParam(text, "VALUE") == "DATE" {
+			t, err := parseDate(propertyValue(text), loc)
 			if err != nil {
-				return nil, err
+				return err
 			}
-			recurrence.RRules = append(recurrence.RRules, rrule)
-		case "EXRULE":
-			rrule, err := ParseRRule(propVal)
-			if err != nil {
-				return nil, err
+			recurrence.Dtend = t
+			return nil
+		}
+
+		t, _, err := parseTime(text, loc)
+		if err != nil {
+			return err
+		}
+		recurrence.Dtend = t
+
+	case "DURATION":
+		days, d, err := parseDuration(propertyValue(text))
+		if err != nil {
+			return err
+		}
+		recurrence.DurationDays = days
+		recurrence.Duration = d
+
+	case "RRULE":
+		rrule, err := p.parseRRule(propVal)
+		if err != nil {
+			return err
+		}
+		recurrence.RRules = append(recurrence.RRules, rrule)
+	case "EXRULE":
+		rrule, err := p.parseRRule(propVal)
+		if err != nil {
+			return err
+		}
+		recurrence.ExRules = append(recurrence.ExRules, rrule)
+	case "RDATE":
+		for _, line := range splitValues(text) {
+			if propertyParam(line, "VALUE") == "DATE" {
+				t, err := parseDate(propertyValue(line), loc)
+				if err != nil {
+					return err
+				}
+
+				*rdays = append(*rdays, t)
+				continue
+			}
+
+			if propertyParam(line, "VALUE") == "PERIOD" {
+				period, err := parsePeriod(line, loc)
+				if err != nil {
+					return err
+				}
+
+				recurrence.RDates = append(recurrence.RDates, period.Start)
+				recurrence.RPeriods = append(recurrence.RPeriods, period)
+				continue
 			}
-			recurrence.ExRules = append(recurrence.ExRules, rrule)
-		case "RDATE":
-			t, _, err := parseTime(propVal, loc)
+
+			t, _, err := parseTime(line, loc)
 			if err != nil {
-				return nil, err
+				return err
 			}
 
 			recurrence.RDates = append(recurrence.RDates, t)
-		case "EXDATE":
-			t, _, err := parseTime(propVal, loc)
+		}
+	case "EXDATE":
+		for _, line := range splitValues(text) {
+			if propertyParam(line, "VALUE") == "DATE" {
+				t, err := parseDate(propertyValue(line), loc)
+				if err != nil {
+					return err
+				}
+
+				recurrence.ExDays = append(recurrence.ExDays, t)
+				continue
+			}
+
+			t, _, err := parseTime(line, loc)
 			if err != nil {
-				return nil, err
+				return err
 			}
 
 			recurrence.ExDates = append(recurrence.ExDates, t)
 		}
+	default:
+		colonIdx := strings.Index(text, ":")
+		if colonIdx < 0 {
+			return fmt.Errorf("misformatted line %q", text)
+		}
+		recurrence.Extras = append(recurrence.Extras, Property{
+			Name:     propName,
+			Params:   strings.TrimPrefix(text[len(propName):colonIdx], ";"),
+			Value:    text[colonIdx+1:],
+			Standard: standardProperties[propName],
+		})
 	}
 
-	recurrence.setDtstart()
+	return nil
+}
 
-	return recurrence, nil
+// standardProperties are the properties defined by RFC 5545, other than those
+// of a recurrence, that may accompany one.
+var standardProperties = map[string]bool{
+	"ACTION": true, "ATTACH": true, "ATTENDEE": true, "BEGIN": true,
+	"CALSCALE": true, "CATEGORIES": true, "CLASS": true, "COMMENT": true,
+	"COMPLETED": true, "CONTACT": true, "CREATED": true, "DESCRIPTION": true,
+	"DTSTAMP": true, "DUE": true, "END": true, "FREEBUSY": true, "GEO": true,
+	"LAST-MODIFIED": true, "LOCATION": true, "METHOD": true, "ORGANIZER": true,
+	"PERCENT-COMPLETE": true, "PRIORITY": true, "PRODID": true,
+	"RECURRENCE-ID": true, "RELATED-TO": true, "REPEAT": true,
+	"REQUEST-STATUS": true, "RESOURCES": true, "SEQUENCE": true, "STATUS": true,
+	"SUMMARY": true, "TRANSP": true, "TRIGGER": true, "TZID": true,
+	"TZNAME": true, "TZOFFSETFROM": true, "TZOFFSETTO": true, "TZURL": true,
+	"UID": true, "URL": true, "VERSION": true,
+}
+
+// reconcileValueTypes checks that RDATE and EXDATE values have the same value
+// type, DATE or DATE-TIME, as DTSTART, as RFC 5545 requires. A mismatch would
+// otherwise silently fail to match any instance. Strict parsers reject
+// mismatches, while lenient ones coerce the values to DTSTART's type.
+//
+// A date-valued EXDATE alongside a date-time DTSTART is always accepted, and
+// excludes the whole day.
+func (p *Parser) reconcileValueTypes(r *Recurrence, rdays []time.Time) error {
+	if r.DateValued {
+		if len(r.RDates) > 0 || len(r.ExDates) > 0 {
+			if !p.Lenient {
+				return errors.New("RDATE and EXDATE values must be dates when DTSTART is a date")
+			}
+
+			for i, rdate := range r.RDates {
+				r.RDates[i] = time.Date(rdate.Year(), rdate.Month(), rdate.Day(), 0, 0, 0, 0, r.Dtstart.Location())
+			}
+			r.RPeriods = nil
+			r.ExDays = append(r.ExDays, r.ExDates...)
+			r.ExDates = nil
+		}
+
+		r.RDates = append(r.RDates, rdays...)
+		return nil
+	}
+
+	if len(rdays) > 0 {
+		if !p.Lenient {
+			return errors.New("RDATE values must be date-times when DTSTART is a date-time")
+		}
+
+		for _, rday := range rdays {
+			r.RDates = append(r.RDates, time.Date(rday.Year(), rday.Month(), rday.Day(), r.Dtstart.Hour(), r.Dtstart.Minute(), r.Dtstart.Second(), 0, rday.Location()))
+		}
+	}
+
+	return nil
 }
 
-// ParseRRule parses a single RRule pattern.
+// ParseRRule parses a single RRule pattern. A part that fails to parse
+// produces a *ParseError locating it, which wraps an *UnsupportedPartError if
+// the part is not supported.
+//
+// When an error is returned, the returned RRule is still populated with every
+// part that parsed successfully, which can help show a user what was
+// understood of a malformed rule. Only the first error is returned.
 func ParseRRule(str string) (RRule, error) {
-	scanner := bufio.NewScanner(bytes.NewBufferString(str))
+	return (&Parser{}).ParseRRule(str)
+}
+
+// ParseRRule is like the package-level ParseRRule, but uses the configuration
+// of p.
+func (p *Parser) ParseRRule(str string) (RRule, error) {
+	p.Skipped = nil
+	return p.parseRRule(str)
+}
+
+// ParseRRuleWithAliases parses a single RRule pattern like ParseRRule, but also
+// accepts the given weekday aliases in BYDAY and WKST. See
+// Parser.WeekdayAliases.
+func ParseRRuleWithAliases(str string, aliases map[string]time.Weekday) (RRule, error) {
+	return (&Parser{WeekdayAliases: aliases}).ParseRRule(str)
+}
+
+func (p *Parser) parseRRule(str string) (RRule, error) {
+	scanner := bufio.NewScanner(bytes.NewBuffer(unfold([]byte(str))))
 	scanner.Split(func(data []byte, atEOF bool) (advance int, token []byte, err error) {
 		if atEOF && len(data) == 0 {
 			return 0, nil, nil
@@ -110,111 +353,280 @@ func ParseRRule(str string) (RRule, error) {
 		return 0, nil, nil
 	})
 
-	rrule := RRule{}
+	rrule := RRule{Raw: str}
+	freqSeen := false
+
+	// parsing continues past a bad part, so the returned rule reflects every
+	// part that could be understood, along with the first error.
+	var firstErr error
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
 
-	for scanner.Scan() {
+	// offset is the position of each part within the unfolded rule
+	offset := 0
+	for ; scanner.Scan(); offset += len(scanner.Bytes()) + 1 {
 		wholeComponent := scanner.Text()
-		parts := strings.SplitN(wholeComponent, "=", 2)
+
+		// some feeds pad parts and values with whitespace, or leave an empty
+		// part behind a stray semicolon, neither of which changes the rule
+		trimmed := strings.TrimSpace(wholeComponent)
+		if trimmed == "" {
+			continue
+		}
+		partOffset := offset + strings.Index(wholeComponent, trimmed)
+
+		parts := strings.SplitN(trimmed, "=", 2)
 		if len(parts) < 2 {
-			return rrule, fmt.Errorf("rrule segment %q is invalid", scanner.Text())
+			fail(&ParseError{Property: trimmed, Offset: partOffset, Err: errors.New("rrule segment is invalid")})
+			continue
 		}
 
-		directive, value := parts[0], parts[1]
+		directive, value := strings.TrimSpace(parts[0]), trimValues(parts[1])
+		wholeComponent = directive + "=" + value
 
-		switch strings.ToUpper(directive) {
-		case "FREQ":
-			freq, err := strToFreq(value)
-			if err != nil {
-				return rrule, err
+		if strings.EqualFold(directive, "FREQ") {
+			if freqSeen {
+				fail(&ParseError{Property: directive, Value: value, Offset: partOffset, Err: errors.New("must not appear more than once")})
+				continue
 			}
-			rrule.Frequency = freq
-		case "UNTIL":
-			t, floating, err := parseTime(wholeComponent, nil)
+			freqSeen = true
+		}
+
+		if err := p.parseRRulePart(&rrule, directive, value, wholeComponent); err != nil {
+			fail(&ParseError{Property: directive, Value: value, Offset: partOffset, Err: err})
+		}
+	}
+
+	if firstErr != nil {
+		return rrule, firstErr
+	}
+
+	err := rrule.Validate()
+	return rrule, err
+}
+
+// trimValues trims whitespace around a part's value and each of the
+// comma-separated values in it.
+func trimValues(value string) string {
+	values := strings.Split(value, ",")
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+	}
+	return strings.Join(values, ",")
+}
+
+// parseUnsigned parses the value of an unsigned integer part, such as COUNT.
+// RFC 5545 allows only digits, but lenient parsers also accept a leading +.
+func (p *Parser) parseUnsigned(value string) (int, error) {
+	if !p.Lenient && strings.TrimLeft(value, "0123456789") != "" {
+		return 0, fmt.Errorf("value %q must contain only digits", value)
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 {
+		return 0, fmt.Errorf("value %q must not be negative", value)
+	}
+	return i, nil
+}
+
+// parseRRulePart parses a single directive=value part of an RRULE into rrule.
+func (p *Parser) parseRRulePart(rrule *RRule, directive, value, wholeComponent string) error {
+	switch strings.ToUpper(directive) {
+	case "FREQ":
+		freq, err := strToFreq(value)
+		if err != nil {
+			return err
+		}
+		rrule.Frequency = freq
+	case "UNTIL":
+		// a date, as in UNTIL=20180830, includes every instance on that day
+		if len(value) == len(rfc5545Date) {
+			t, err := parseDate(value, nil)
 			if err != nil {
-				return rrule, err
+				return err
 			}
 			rrule.Until = t
-			rrule.UntilFloating = floating
+			rrule.UntilFloating = true
+			rrule.UntilDate = true
+			break
+		}
 
-		case "COUNT":
-			i, err := strconv.Atoi(value)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.Count = uint64(i)
-		case "INTERVAL":
-			i, err := strconv.Atoi(value)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.Interval = i
-		case "BYSECOND":
-			ints, err := parseInts(value)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.BySeconds = ints
-		case "BYMINUTE":
-			ints, err := parseInts(value)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.ByMinutes = ints
-		case "BYHOUR":
-			ints, err := parseInts(value)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.ByHours = ints
-		case "BYDAY":
-			wds, err := parseQualifiedWeekdays(value)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.ByWeekdays = wds
-		case "BYMONTHDAY":
-			ints, err := parseInts(value)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.ByMonthDays = ints
-		case "BYYEARDAY":
-			ints, err := parseInts(value)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.ByYearDays = ints
-		case "BYWEEKNO":
-			ints, err := parseInts(value)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.ByWeekNumbers = ints
-		case "BYMONTH":
-			months, err := parseMonths(value)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.ByMonths = months
-		case "BYSETPOS":
-			ints, err := parseInts(value)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.BySetPos = ints
-		case "WKST":
-			wd, err := parseWeekday(value)
-			if err != nil {
-				return rrule, err
-			}
-			rrule.WeekStart = &wd
+		t, floating, err := parseTime(wholeComponent, nil)
+		if err != nil {
+			return err
+		}
+		rrule.Until = t
+		rrule.UntilFloating = floating
+
+	case "COUNT":
+		i, err := p.parseUnsigned(value)
+		if err != nil {
+			return err
+		}
+		rrule.Count = uint64(i)
+	case "INTERVAL":
+		i, err := p.parseUnsigned(value)
+		if err != nil {
+			return err
+		}
+		rrule.Interval = i
+	case "BYSECOND":
+		ints, err := parseInts(value)
+		if err != nil {
+			return err
+		}
+		rrule.BySeconds = ints
+	case "BYMINUTE":
+		ints, err := parseInts(value)
+		if err != nil {
+			return err
+		}
+		rrule.ByMinutes = ints
+	case "BYHOUR":
+		ints, err := parseInts(value)
+		if err != nil {
+			return err
+		}
+		rrule.ByHours = ints
+	case "BYDAY":
+		wds, err := parseQualifiedWeekdays(value, p.WeekdayAliases)
+		if err != nil {
+			return err
+		}
+		rrule.ByWeekdays = wds
+	case "BYMONTHDAY":
+		ints, err := parseInts(value)
+		if err != nil {
+			return err
+		}
+		rrule.ByMonthDays = ints
+	case "BYYEARDAY":
+		ints, err := parseInts(value)
+		if err != nil {
+			return err
+		}
+		rrule.ByYearDays = ints
+	case "BYWEEKNO":
+		ints, err := parseInts(value)
+		if err != nil {
+			return err
+		}
+		rrule.ByWeekNumbers = ints
+	case "BYMONTH":
+		months, err := parseMonths(value)
+		if err != nil {
+			return err
+		}
+		rrule.ByMonths = months
+	case "BYSETPOS":
+		ints, err := parseInts(value)
+		if err != nil {
+			return err
+		}
+		rrule.BySetPos = ints
+	case "WKST":
+		wd, err := parseWeekday(value, p.WeekdayAliases)
+		if err != nil {
+			return err
+		}
+		rrule.WeekStart = &wd
+	default:
+		partErr := &UnsupportedPartError{Part: directive}
+		if !p.Lenient {
+			return partErr
+		}
+		p.Skipped = append(p.Skipped, partErr)
+	}
+
+	return nil
+}
+
+// propertyParam returns the value of the named parameter of a content line,
+// such as "DATE" for VALUE in "EXDATE;VALUE=DATE:20180825", or the empty
+// string if the parameter is absent.
+func propertyParam(line, name string) string {
+	if colonIdx := strings.Index(line, ":"); colonIdx >= 0 {
+		line = line[:colonIdx]
+	}
+
+	params := strings.Split(line, ";")
+	for _, param := range params[1:] {
+		parts := strings.SplitN(param, "=", 2)
+		if len(parts) == 2 && strings.EqualFold(parts[0], name) {
+			return parts[1]
+		}
+	}
+
+	return ""
+}
+
+// propertyValue returns the value of a content line, which follows the
+// property name and parameters.
+func propertyValue(line string) string {
+	return line[strings.Index(line, ":")+1:]
+}
+
+// splitValues splits a content line with a comma-separated list of values,
+// such as "EXDATE:20180101T000000Z,20180201T000000Z", into a line for each
+// value, with the same property name and parameters.
+func splitValues(line string) []string {
+	colonIdx := strings.Index(line, ":")
+	if colonIdx < 0 {
+		return []string{line}
+	}
+
+	values := strings.Split(line[colonIdx+1:], ",")
+	lines := make([]string, len(values))
+	for i, value := range values {
+		lines[i] = line[:colonIdx+1] + value
+	}
+	return lines
+}
+
+// unfold joins lines that were folded as described in RFC 5545 section 3.1,
+// where a long line is split by inserting a line break followed by a single
+// space or tab.
+func unfold(src []byte) []byte {
+	out := make([]byte, 0, len(src))
+	for i := 0; i < len(src); i++ {
+		switch {
+		case src[i] == '\r' && i+2 < len(src) && src[i+1] == '\n' && isFoldSpace(src[i+2]):
+			i += 2
+		case src[i] == '\n' && i+1 < len(src) && isFoldSpace(src[i+1]):
+			i++
 		default:
-			return rrule, fmt.Errorf("%q is not a supported RRULE part", directive)
+			out = append(out, src[i])
 		}
 	}
+	return out
+}
 
-	err := rrule.Validate()
-	return rrule, err
+// contentLineStarts returns the 1-based line of src where each line of the
+// unfolded src begins.
+func contentLineStarts(src []byte) []int {
+	starts := []int{1}
+	line := 1
+	for i := 0; i < len(src); i++ {
+		if src[i] != '\n' {
+			continue
+		}
+		line++
+		if i+1 < len(src) && isFoldSpace(src[i+1]) {
+			continue
+		}
+		starts = append(starts, line)
+	}
+	return starts
+}
+
+func isFoldSpace(b byte) bool {
+	return b == ' ' || b == '\t'
 }
 
 func parseInts(str string) ([]int, error) {
@@ -234,7 +646,7 @@ func parseInts(str string) ([]int, error) {
 	return ints, nil
 }
 
-func parseQualifiedWeekdays(str string) ([]QualifiedWeekday, error) {
+func parseQualifiedWeekdays(str string, aliases map[string]time.Weekday) ([]QualifiedWeekday, error) {
 	var err error
 	parts := strings.Split(str, ",")
 	wds := make([]QualifiedWeekday, len(parts))
@@ -265,7 +677,7 @@ func parseQualifiedWeekdays(str string) ([]QualifiedWeekday, error) {
 			}
 		}
 
-		wd, err := parseWeekday(p[idx:])
+		wd, err := parseWeekday(p[idx:], aliases)
 		if err != nil {
 			return nil, err
 		}
@@ -276,7 +688,15 @@ func parseQualifiedWeekdays(str string) ([]QualifiedWeekday, error) {
 	return wds, nil
 }
 
-func parseWeekday(str string) (time.Weekday, error) {
+// parseWeekday parses a two-letter weekday. aliases, if not nil, is consulted
+// case-insensitively before the standard RFC 5545 abbreviations.
+func parseWeekday(str string, aliases map[string]time.Weekday) (time.Weekday, error) {
+	for alias, wd := range aliases {
+		if strings.EqualFold(alias, str) {
+			return wd, nil
+		}
+	}
+
 	switch strings.ToLower(str) {
 	case "mo":
 		return time.Monday, nil
@@ -311,6 +731,8 @@ func parseMonths(str string) ([]time.Month, error) {
 	return months, nil
 }
 
+// strToFreq parses a frequency. Only the full RFC 5545 names are accepted,
+// case-insensitively, so DAILY and Daily are both valid but DAY is not.
 func strToFreq(str string) (Frequency, error) {
 	switch strings.ToLower(str) {
 	case "secondly":
@@ -328,6 +750,6 @@ func strToFreq(str string) (Frequency, error) {
 	case "yearly":
 		return Yearly, nil
 	default:
-		return Yearly, fmt.Errorf("frequency %q is not valid", str)
+		return Yearly, fmt.Errorf("frequency %q is not valid; must be one of SECONDLY, MINUTELY, HOURLY, DAILY, WEEKLY, MONTHLY, or YEARLY", str)
 	}
 }
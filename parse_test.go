@@ -1,5 +1,367 @@
 package rrule
 
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
 func ExampleParseRRule() {
 	ParseRRule("FREQ=WEEKLY;BYDAY=1MO,2TU;COUNT=2")
 }
+
+func TestParseRRuleUnsupportedPart(t *testing.T) {
+	const str = "FREQ=DAILY;X-VENDOR=1;COUNT=3"
+
+	_, err := ParseRRule(str)
+	var partErr *UnsupportedPartError
+	require.True(t, errors.As(err, &partErr))
+	assert.Equal(t, "X-VENDOR", partErr.Part)
+
+	p := &Parser{Lenient: true}
+	rrule, err := p.ParseRRule(str)
+	require.NoError(t, err)
+	assert.Equal(t, RRule{Frequency: Daily, Count: 3, Raw: str}, rrule)
+	require.Len(t, p.Skipped, 1)
+	assert.Equal(t, "X-VENDOR", p.Skipped[0].Part)
+}
+
+func TestParseRecurrenceFolded(t *testing.T) {
+	src := "DTSTART:20180825T090807Z\r\nRRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,\r\n FR\r\n"
+
+	r, err := ParseRecurrence([]byte(src), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, r.RRules, 1)
+	assert.Equal(t, []QualifiedWeekday{
+		{WD: time.Monday}, {WD: time.Tuesday}, {WD: time.Wednesday}, {WD: time.Thursday}, {WD: time.Friday},
+	}, r.RRules[0].ByWeekdays)
+
+	rrule, err := ParseRRule("FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,\r\n FR")
+	require.NoError(t, err)
+	assert.Len(t, rrule.ByWeekdays, 5)
+
+	// a long line folded across three physical lines, with a space and a tab
+	src = "DTSTART:20180825T090807Z\r\nRRULE:FREQ=YEARLY;BYYEARDAY=1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,\r\n" +
+		" 20,21,22,23,24,25,26,27,28,29,30,31,32,33,34,35,36,37,38,39,40,41,42,43,44,45,\r\n" +
+		"\t46,47,48,49,50;COUNT=50\r\n"
+	r, err = ParseRecurrence([]byte(src), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, r.RRules, 1)
+	assert.Len(t, r.RRules[0].ByYearDays, 50)
+	assert.Equal(t, 50, r.RRules[0].ByYearDays[49])
+	assert.Equal(t, uint64(50), r.RRules[0].Count)
+}
+
+func TestParseRecurrenceValueLists(t *testing.T) {
+	src := "DTSTART;TZID=America/New_York:20180101T090000\n" +
+		"RRULE:FREQ=DAILY;COUNT=10\n" +
+		"RDATE:20180201T000000Z,20180301T000000Z\n" +
+		"EXDATE;TZID=America/New_York:20180102T090000,20180103T090000\n" +
+		"EXDATE;VALUE=DATE:20180104,20180105\n"
+
+	r, err := ParseRecurrence([]byte(src), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC),
+	}, r.RDates)
+	assert.Equal(t, []string{"2018-01-02T09:00:00-05:00", "2018-01-03T09:00:00-05:00"}, rfcAll(r.ExDates))
+	assert.Equal(t, []string{"2018-01-04T00:00:00Z", "2018-01-05T00:00:00Z"}, rfcAll(r.ExDays))
+	assert.Len(t, All(r.Iterator(), 0), 8)
+
+	// fifty values folded across three physical lines
+	var values []string
+	for i := 0; i < 50; i++ {
+		values = append(values, time.Date(2018, 1, 1, 9, 0, 0, 0, time.UTC).AddDate(0, 0, i).Format(rfc5545WithOffset))
+	}
+	exdate := "EXDATE:" + strings.Join(values, ",")
+	src = "DTSTART:20180101T090000Z\r\nRRULE:FREQ=DAILY;COUNT=60\r\n" + exdate[:300] + "\r\n " + exdate[300:600] + "\r\n\t" + exdate[600:] + "\r\n"
+
+	r, err = ParseRecurrence([]byte(src), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, r.ExDates, 50)
+	assert.Equal(t, "2018-02-19T09:00:00Z", r.ExDates[49].Format(time.RFC3339))
+	assert.Len(t, All(r.Iterator(), 0), 10)
+}
+
+func TestParseRecurrencePeriods(t *testing.T) {
+	src := "DTSTART:19970101T090000Z\n" +
+		"RRULE:FREQ=DAILY;COUNT=2\n" +
+		"RDATE;VALUE=PERIOD:19970101T180000Z/19970102T070000Z,19970105T180000Z/PT5H30M\n" +
+		"RDATE;VALUE=PERIOD;TZID=America/New_York:19970110T090000/PT0S\n"
+
+	r, err := ParseRecurrence([]byte(src), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1997-01-01T18:00:00Z", "1997-01-05T18:00:00Z", "1997-01-10T09:00:00-05:00"}, rfcAll(r.RDates))
+	require.Len(t, r.RPeriods, 3)
+	assert.Equal(t, "1997-01-02T07:00:00Z", r.RPeriods[0].End.Format(time.RFC3339))
+	assert.Equal(t, "1997-01-05T23:30:00Z", r.RPeriods[1].End.Format(time.RFC3339))
+
+	r.Duration = time.Hour
+	ranges := r.Events(0)
+	require.Len(t, ranges, 5)
+	assert.Equal(t, Period{Start: r.Dtstart, End: r.Dtstart.Add(time.Hour)}, ranges[0])
+	assert.Equal(t, r.RPeriods[0], ranges[1])
+	assert.Equal(t, r.RPeriods[1], ranges[3])
+
+	assert.Equal(t, "DTSTART:19970101T090000Z\n"+
+		"DURATION:PT1H\n"+
+		"RRULE:FREQ=DAILY;COUNT=2\n"+
+		"RDATE;VALUE=PERIOD:19970101T180000Z/19970102T070000Z\n"+
+		"RDATE;VALUE=PERIOD:19970105T180000Z/19970105T233000Z\n"+
+		"RDATE;VALUE=PERIOD;TZID=America/New_York:19970110T090000/19970110T090000\n", r.String())
+
+	for _, value := range []string{"19970101T180000Z", "19970101T180000Z/19970101T170000Z", "19970101T180000Z/PT", "19970101T180000Z/P1H", "19970101T180000Z/-PT1H"} {
+		_, err := ParseRecurrence([]byte("DTSTART:19970101T090000Z\nRDATE;VALUE=PERIOD:"+value), time.UTC)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseRecurrenceExtras(t *testing.T) {
+	src := "DTSTART:20180101T090000Z\n" +
+		"X-WR-TIMEZONE:America/New_York\n" +
+		"RRULE:FREQ=DAILY;COUNT=2\n" +
+		"SUMMARY;LANGUAGE=en:Stand-up\n" +
+		"FLAVOR:vanilla\n"
+
+	r, err := ParseRecurrence([]byte(src), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, []Property{
+		{Name: "X-WR-TIMEZONE", Value: "America/New_York"},
+		{Name: "SUMMARY", Params: "LANGUAGE=en", Value: "Stand-up", Standard: true},
+		{Name: "FLAVOR", Value: "vanilla"},
+	}, r.Extras)
+
+	assert.Equal(t, "DTSTART:20180101T090000Z\n"+
+		"RRULE:FREQ=DAILY;COUNT=2\n"+
+		"X-WR-TIMEZONE:America/New_York\n"+
+		"SUMMARY;LANGUAGE=en:Stand-up\n"+
+		"FLAVOR:vanilla\n", r.String())
+
+	reparsed, err := ParseRecurrence([]byte(r.String()), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, r.Extras, reparsed.Extras)
+
+	// a property with parameters but no value
+	_, err = ParseRecurrence([]byte("DTSTART:20180825T090000Z\nFOO;X=a"), time.UTC)
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, 2, parseErr.Line)
+	assert.Equal(t, "FOO", parseErr.Property)
+}
+
+func TestParseRecurrenceCRLF(t *testing.T) {
+	src := "\xef\xbb\xbfDTSTART;TZID=America/New_York:20180101T090000\r\nRRULE:FREQ=DAILY;COUNT=2\r\nEXDATE;TZID=America/New_York:20180102T090000\r\n"
+
+	r, err := ParseRecurrence([]byte(src), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, "2018-01-01T09:00:00-05:00", r.Dtstart.Format(time.RFC3339))
+	assert.Equal(t, NewYork().String(), r.Location().String())
+	assert.Equal(t, []string{"2018-01-01T09:00:00-05:00"}, rfcAll(All(r.Iterator(), 0)))
+	assert.Equal(t, "DTSTART;TZID=America/New_York:20180101T090000", r.Raw[0])
+}
+
+func TestParseRRuleWithAliases(t *testing.T) {
+	german := map[string]time.Weekday{
+		"MO": time.Monday,
+		"DI": time.Tuesday,
+		"MI": time.Wednesday,
+		"DO": time.Thursday,
+		"FR": time.Friday,
+		"SA": time.Saturday,
+		"SO": time.Sunday,
+	}
+
+	rrule, err := ParseRRuleWithAliases("FREQ=MONTHLY;BYDAY=DI,-1so;WKST=SO", german)
+	require.NoError(t, err)
+	assert.Equal(t, []QualifiedWeekday{{WD: time.Tuesday}, {N: -1, WD: time.Sunday}}, rrule.ByWeekdays)
+	require.NotNil(t, rrule.WeekStart)
+	assert.Equal(t, time.Sunday, *rrule.WeekStart)
+
+	_, err = ParseRRule("FREQ=MONTHLY;BYDAY=DI")
+	assert.Error(t, err)
+}
+
+func TestParseRRuleFrequency(t *testing.T) {
+	for _, str := range []string{"FREQ=DAILY", "FREQ=daily", "FREQ=Daily"} {
+		rrule, err := ParseRRule(str)
+		require.NoError(t, err, str)
+		assert.Equal(t, Daily, rrule.Frequency, str)
+	}
+
+	for _, str := range []string{"FREQ=WEEK", "FREQ=D", "FREQ=", "FREQ=DAILY;FREQ=WEEKLY"} {
+		_, err := ParseRRule(str)
+		assert.Error(t, err, str)
+	}
+
+	_, err := ParseRRule("FREQ=WEEK")
+	assert.EqualError(t, err, `FREQ at offset 0: frequency "WEEK" is not valid; must be one of SECONDLY, MINUTELY, HOURLY, DAILY, WEEKLY, MONTHLY, or YEARLY`)
+}
+
+func TestParseRRuleWhitespace(t *testing.T) {
+	want := MustRRule("FREQ=WEEKLY;COUNT=3;BYDAY=MO,TU").String()
+	for _, str := range []string{
+		"FREQ= WEEKLY;COUNT=3;BYDAY=MO,TU",
+		" FREQ = WEEKLY ; COUNT = 3 ; BYDAY = MO , TU ",
+		"FREQ=WEEKLY;COUNT=3;BYDAY=MO,TU;",
+		"FREQ=WEEKLY;;COUNT=3;BYDAY=MO,TU; ",
+		"freq=weekly;count=3;byday=MO,TU",
+	} {
+		rrule, err := ParseRRule(str)
+		require.NoError(t, err, str)
+		assert.Equal(t, want, rrule.String(), str)
+	}
+
+	_, err := ParseRRule("FREQ=WEEKLY; COUNT=x")
+	assert.EqualError(t, err, `COUNT at offset 13: value "x" must contain only digits`)
+
+	r, err := ParseRecurrence([]byte("DTSTART:20180101T090000Z\nRRULE: FREQ=DAILY; UNTIL=20180103T090000Z"), time.UTC)
+	require.NoError(t, err)
+	assert.Len(t, All(r.Iterator(), 0), 3)
+}
+
+func TestParseRRuleUntilDate(t *testing.T) {
+	rrule, err := ParseRRule("FREQ=DAILY;UNTIL=20180827")
+	require.NoError(t, err)
+	assert.True(t, rrule.UntilDate)
+	assert.Equal(t, "FREQ=DAILY;UNTIL=20180827", rrule.String())
+
+	// the day of UNTIL is included in the location of DTSTART, and a
+	// pattern ending on the day it starts is valid
+	r, err := ParseRecurrence([]byte("DTSTART;TZID=America/New_York:20180825T210000\nRRULE:FREQ=DAILY;UNTIL=20180827"), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2018-08-25T21:00:00-04:00", "2018-08-26T21:00:00-04:00", "2018-08-27T21:00:00-04:00"}, rfcAll(r.All(0)))
+
+	_, err = ParseRecurrence([]byte("DTSTART:20180825T210000Z\nRRULE:FREQ=DAILY;UNTIL=20180825"), time.UTC)
+	assert.NoError(t, err)
+
+	// an UNTIL before DTSTART is parsed, but the pattern has no instances
+	r, err = ParseRecurrence([]byte("DTSTART:20180825T210000Z\nRRULE:FREQ=DAILY;UNTIL=20180824\nRDATE:20180901T090000Z"), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2018-09-01T09:00:00Z"}, rfcAll(r.All(0)))
+	assert.Error(t, r.Validate())
+
+	_, err = ParseRRule("FREQ=DAILY;UNTIL=2018082X")
+	assert.Error(t, err)
+}
+
+func TestParseRRuleUnsigned(t *testing.T) {
+	for _, str := range []string{"FREQ=DAILY;INTERVAL=+3", "FREQ=DAILY;COUNT=+5", "FREQ=DAILY;COUNT=-5", "FREQ=DAILY;INTERVAL=-1"} {
+		_, err := ParseRRule(str)
+		assert.Error(t, err, str)
+	}
+
+	_, err := ParseRRule("FREQ=DAILY;INTERVAL=+3")
+	assert.EqualError(t, err, `INTERVAL at offset 11: value "+3" must contain only digits`)
+
+	lenient := &Parser{Lenient: true}
+	rrule, err := lenient.ParseRRule("FREQ=DAILY;INTERVAL=+3;COUNT=+5")
+	require.NoError(t, err)
+	assert.Equal(t, 3, rrule.Interval)
+	assert.Equal(t, uint64(5), rrule.Count)
+
+	_, err = lenient.ParseRRule("FREQ=DAILY;COUNT=-5")
+	assert.Error(t, err, "negative values are rejected even when lenient")
+}
+
+func TestParseError(t *testing.T) {
+	_, err := ParseRRule("FREQ=DAILY;COUNT=x;BYDAY=MO")
+	var parseErr *ParseError
+	require.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 0, parseErr.Line)
+	assert.Equal(t, "COUNT", parseErr.Property)
+	assert.Equal(t, "x", parseErr.Value)
+	assert.Equal(t, 11, parseErr.Offset)
+
+	_, err = ParseRRule("FREQ=DAILY;X-VENDOR=1")
+	require.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 11, parseErr.Offset)
+	var partErr *UnsupportedPartError
+	assert.True(t, errors.As(err, &partErr), "the underlying error is unwrapped")
+
+	src := "DTSTART:20180825T090807Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,\n TU;BYMONTH=1\nRDATE:2018\n"
+	_, err = ParseRecurrence([]byte(src), time.UTC)
+	require.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 4, parseErr.Line)
+	assert.Equal(t, "RDATE", parseErr.Property)
+	assert.Equal(t, "2018", parseErr.Value)
+	assert.Equal(t, 0, parseErr.Offset)
+
+	src = "DTSTART:20180825T090807Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,\n TU;BYMONTH=x\n"
+	_, err = ParseRecurrence([]byte(src), time.UTC)
+	require.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 2, parseErr.Line)
+	assert.Equal(t, "BYMONTH", parseErr.Property)
+	assert.Equal(t, "x", parseErr.Value)
+	assert.Equal(t, 30, parseErr.Offset)
+	assert.Regexp(t, `^line 2: BYMONTH at offset 30: `, err.Error())
+
+	_, err = ParseRecurrence([]byte("DTSTART:20180825T090807Z\nnonsense\n"), time.UTC)
+	assert.EqualError(t, err, `line 2: misformatted line "nonsense"`)
+}
+
+func TestParseRecurrenceValueTypes(t *testing.T) {
+	dateStart := "DTSTART;VALUE=DATE:20180825\nRRULE:FREQ=DAILY;COUNT=4\nEXDATE:20180826T000000Z\nRDATE:20180901T120000Z\n"
+
+	_, err := ParseRecurrence([]byte(dateStart), time.UTC)
+	assert.Error(t, err)
+
+	r, err := (&Parser{Lenient: true}).ParseRecurrence([]byte(dateStart), time.UTC)
+	require.NoError(t, err)
+	assert.True(t, r.DateValued)
+	assert.Equal(t, []string{"2018-08-25T00:00:00Z", "2018-08-27T00:00:00Z", "2018-08-28T00:00:00Z", "2018-09-01T00:00:00Z"}, rfcAll(All(r.Iterator(), 0)))
+	assert.Equal(t, "DTSTART;VALUE=DATE:20180825\nRRULE:FREQ=DAILY;COUNT=4\nRDATE;VALUE=DATE:20180901\nEXDATE;VALUE=DATE:20180826\n", r.String())
+
+	dateTimeStart := "DTSTART:20180825T090000Z\nRRULE:FREQ=DAILY;COUNT=2\nRDATE;VALUE=DATE:20180901\n"
+
+	_, err = ParseRecurrence([]byte(dateTimeStart), time.UTC)
+	assert.Error(t, err)
+
+	r, err = (&Parser{Lenient: true}).ParseRecurrence([]byte(dateTimeStart), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2018-08-25T09:00:00Z", "2018-08-26T09:00:00Z", "2018-09-01T09:00:00Z"}, rfcAll(All(r.Iterator(), 0)))
+}
+
+func TestParseRRulePartial(t *testing.T) {
+	rrule, err := ParseRRule("FREQ=WEEKLY;COUNT=ten;BYDAY=TU,TH;INTERVAL=2")
+	assert.Error(t, err)
+	assert.Equal(t, RRule{
+		Frequency:  Weekly,
+		Interval:   2,
+		ByWeekdays: []QualifiedWeekday{{WD: time.Tuesday}, {WD: time.Thursday}},
+		Raw:        "FREQ=WEEKLY;COUNT=ten;BYDAY=TU,TH;INTERVAL=2",
+	}, rrule)
+}
+
+func TestParseRaw(t *testing.T) {
+	const str = "freq=daily;interval=1;byday=mo,we"
+
+	rrule, err := ParseRRule(str)
+	require.NoError(t, err)
+	assert.Equal(t, str, rrule.Raw)
+	assert.Equal(t, str, rrule.RawString())
+	assert.Equal(t, "FREQ=DAILY;BYDAY=MO,WE", rrule.String())
+
+	constructed := RRule{Frequency: Daily, Count: 2}
+	assert.Empty(t, constructed.Raw)
+	assert.Equal(t, "FREQ=DAILY;COUNT=2", constructed.RawString())
+	assert.Empty(t, rrule.WithCount(2).Raw)
+
+	const src = "DTSTART:20180825T090807Z\nRRULE:freq=weekly;count=2\nX-IGNORED:1\nEXDATE:20180901T090807Z\n"
+
+	r, err := ParseRecurrence([]byte(src), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DTSTART:20180825T090807Z", "RRULE:freq=weekly;count=2", "X-IGNORED:1", "EXDATE:20180901T090807Z"}, r.Raw)
+	assert.Equal(t, "freq=weekly;count=2", r.RRules[0].Raw)
+	assert.Equal(t, src, r.RawString())
+
+	shifted, err := r.Shift(time.Hour)
+	require.NoError(t, err)
+	assert.Nil(t, shifted.Raw)
+	assert.Empty(t, shifted.RRules[0].Raw)
+	assert.Equal(t, shifted.String(), shifted.RawString())
+}
@@ -0,0 +1,591 @@
+package rrule
+
+import (
+	"fmt"
+	"time"
+)
+
+// CountUntil returns the number of occurrences of the pattern up to and
+// including target. It iterates lazily, stopping at the first occurrence past
+// target, so it's safe to use with infinite patterns. Paired with WithCount,
+// it converts an "end by" date into a COUNT.
+func (rrule RRule) CountUntil(target time.Time) int {
+	it := rrule.Iterator()
+
+	count := 0
+	for {
+		next := it.Next()
+		if next == nil || next.After(target) {
+			return count
+		}
+		count++
+	}
+}
+
+// CountRemaining returns the number of occurrences of the pattern after the
+// given time, counting without retaining them. The boolean is false, and the
+// count 0, if the pattern is infinite.
+func (rrule RRule) CountRemaining(after time.Time) (int, bool) {
+	if rrule.IsInfinite() {
+		return 0, false
+	}
+
+	it := rrule.fastForward(after).Iterator()
+
+	count := 0
+	for next := it.Next(); next != nil; next = it.Next() {
+		if next.After(after) {
+			count++
+		}
+	}
+	return count, true
+}
+
+// First returns the first occurrence of the pattern. Following RFC 5545,
+// Dtstart is only an occurrence when it matches the pattern, so the first
+// occurrence is later than Dtstart when, for example, BYDAY excludes the
+// weekday of Dtstart. The boolean is false if the pattern has no occurrences.
+func (rrule RRule) First() (time.Time, bool) {
+	next := rrule.Iterator().Next()
+	if next == nil {
+		return time.Time{}, false
+	}
+	return *next, true
+}
+
+// GetNth returns the nth occurrence of the pattern, counting from 0, without
+// retaining the occurrences before it. A negative n counts back from the last
+// occurrence, so -1 is the last, and is only supported for patterns bounded
+// by COUNT or UNTIL. The boolean is false if there is no such occurrence.
+func (rrule RRule) GetNth(n int) (time.Time, bool) {
+	it := rrule.Iterator()
+
+	if n >= 0 {
+		for i := 0; ; i++ {
+			next := it.Next()
+			if next == nil {
+				return time.Time{}, false
+			}
+			if i == n {
+				return *next, true
+			}
+		}
+	}
+
+	if rrule.IsInfinite() {
+		return time.Time{}, false
+	}
+
+	// keep only the last -n occurrences, in a ring that grows as they
+	// arrive, so a large n allocates no more than the pattern has. -n itself
+	// overflows for the most negative int, so n is only ever added to.
+	var ring []time.Time
+	seen := 0
+	for next := it.Next(); next != nil; next = it.Next() {
+		if len(ring)+n < 0 {
+			ring = append(ring, *next)
+		} else {
+			ring[seen%len(ring)] = *next
+		}
+		seen++
+	}
+	if len(ring)+n < 0 {
+		return time.Time{}, false
+	}
+	return ring[seen%len(ring)], true
+}
+
+// WithCount returns a copy of the pattern that ends after n occurrences. Until
+// is cleared, because COUNT and UNTIL must not both be set.
+func (rrule RRule) WithCount(n int) RRule {
+	rrule.Count = uint64(n)
+	rrule.Until = time.Time{}
+	rrule.UntilFloating = false
+	rrule.UntilDate = false
+	rrule.Raw = ""
+	return rrule
+}
+
+// ToCount returns a copy of the pattern with Until replaced by the equivalent
+// Count. A pattern already bounded by Count is returned unchanged. The boolean
+// is false if the pattern is infinite, or if it has no occurrences at all,
+// since a COUNT of zero can't be expressed.
+func (rrule RRule) ToCount() (RRule, bool) {
+	if rrule.Count != 0 {
+		return rrule, true
+	}
+	if rrule.Until.IsZero() {
+		return rrule, false
+	}
+
+	count := len(All(rrule.Iterator(), 0))
+	if count == 0 {
+		return rrule, false
+	}
+
+	return rrule.WithCount(count), true
+}
+
+// ToUntil returns a copy of the pattern with Count replaced by an Until at the
+// instant of the last occurrence. A pattern already bounded by Until is
+// returned unchanged. The boolean is false if the pattern is infinite.
+func (rrule RRule) ToUntil() (RRule, bool) {
+	if !rrule.Until.IsZero() {
+		return rrule, true
+	}
+	if rrule.Count == 0 {
+		return rrule, false
+	}
+
+	var last *time.Time
+	it := rrule.Iterator()
+	for next := it.Next(); next != nil; next = it.Next() {
+		last = next
+	}
+	if last == nil {
+		return rrule, false
+	}
+
+	rrule.Count = 0
+	rrule.Until = *last
+	rrule.Raw = ""
+	return rrule, true
+}
+
+// StopReason describes why generation of instances stopped.
+type StopReason int
+
+// Reasons generation may stop.
+const (
+	// Exhausted means the pattern has no more instances before Go's
+	// maximum useful time.
+	Exhausted StopReason = iota
+
+	// ByCount means the pattern's COUNT was reached.
+	ByCount
+
+	// ByUntil means the pattern's UNTIL was passed.
+	ByUntil
+
+	// ByLimit means the requested limit was reached, and more instances
+	// remain.
+	ByLimit
+)
+
+func (sr StopReason) String() string {
+	switch sr {
+	case Exhausted:
+		return "exhausted"
+	case ByCount:
+		return "count"
+	case ByUntil:
+		return "until"
+	case ByLimit:
+		return "limit"
+	}
+	return fmt.Sprintf("StopReason(%d)", int(sr))
+}
+
+// AllWithReason returns instances of the pattern up to a limited number, like
+// All, along with the reason generation stopped. A reason of ByLimit means
+// more instances remain, and a caller paging through them should continue
+// after the last one returned. If the limit is reached exactly as the pattern
+// ends, the pattern's own reason is reported instead. An infinite pattern cut
+// off at DefaultHorizon also reports ByLimit.
+func (rrule RRule) AllWithReason(limit int) ([]time.Time, StopReason) {
+	horizon := limit == 0 && rrule.IsInfinite()
+	bounded, limit := rrule.boundedIterator(limit)
+	it := bounded.(*iterator)
+
+	var all []time.Time
+	for {
+		if limit > 0 && len(all) == limit {
+			if it.Peek() != nil {
+				return all, ByLimit
+			}
+			break
+		}
+
+		next := it.Next()
+		if next == nil {
+			break
+		}
+		all = append(all, *next)
+	}
+
+	switch {
+	case it.queueCap > 0 && it.totalQueued >= it.queueCap:
+		return all, ByCount
+	case it.pastMaxTime && !rrule.Until.IsZero():
+		return all, ByUntil
+	case it.pastMaxTime && horizon:
+		return all, ByLimit
+	default:
+		return all, Exhausted
+	}
+}
+
+// NextNFrom returns up to n instances of the pattern at or after from. Unlike
+// limiting All, n counts only instances from the given time onward. The
+// pattern's own COUNT and UNTIL still end the series, and COUNT is always
+// counted from Dtstart, so fewer than n instances are returned if the pattern
+// ends first.
+func (rrule RRule) NextNFrom(from time.Time, n int) []time.Time {
+	if n <= 0 {
+		return nil
+	}
+
+	it := rrule.Iterator()
+
+	all := make([]time.Time, 0, n)
+	for len(all) < n {
+		next := it.Next()
+		if next == nil {
+			break
+		}
+		if next.Before(from) {
+			continue
+		}
+		all = append(all, *next)
+	}
+	return all
+}
+
+// AllFunc returns instances of the pattern up to a limited number, like All,
+// but drops any instance for which skip returns true. This allows exclusions
+// that can't be expressed as EXDATE or EXRULE, such as a list of holidays.
+//
+// The limit counts only returned instances, so skipped ones don't use it up.
+// The pattern's COUNT, however, counts every instance of the pattern, skipped
+// or not, just as an EXDATE doesn't extend a COUNT.
+func (rrule RRule) AllFunc(limit int, skip func(time.Time) bool) []time.Time {
+	it, limit := rrule.boundedIterator(limit)
+
+	var all []time.Time
+	for {
+		next := it.Next()
+		if next == nil {
+			break
+		}
+		if skip(*next) {
+			continue
+		}
+		all = append(all, *next)
+		if limit > 0 && len(all) == limit {
+			break
+		}
+	}
+	return all
+}
+
+// Upcoming returns the instances of the pattern after now and up to and
+// including now+horizon. Polling with consecutive windows, passing the previous
+// window's end as now, returns every instance exactly once, since an instance
+// on the boundary belongs only to the window it ends.
+func (rrule RRule) Upcoming(now time.Time, horizon time.Duration) []time.Time {
+	end := now.Add(horizon)
+	it := rrule.fastForward(now).Iterator()
+
+	var upcoming []time.Time
+	for {
+		next := it.Next()
+		if next == nil || next.After(end) {
+			break
+		}
+		if next.After(now) {
+			upcoming = append(upcoming, *next)
+		}
+	}
+	return upcoming
+}
+
+// FillRing returns the next k instances of the pattern after now, or fewer if
+// the pattern ends first, for refilling a fixed-size buffer of upcoming fire
+// times. Generation stops as soon as k instances are found, and the returned
+// slice is allocated once with capacity k.
+func (rrule RRule) FillRing(now time.Time, k int) []time.Time {
+	if k <= 0 {
+		return nil
+	}
+
+	it := rrule.fastForward(now).Iterator()
+
+	ring := make([]time.Time, 0, k)
+	for len(ring) < k {
+		next := it.Next()
+		if next == nil {
+			break
+		}
+		if next.After(now) {
+			ring = append(ring, *next)
+		}
+	}
+	return ring
+}
+
+// fastForward returns a copy of the pattern with Dtstart moved to its latest
+// instance at or before t, so iteration needn't step through every earlier
+// instance. Only simple patterns without COUNT, whose periods are a fixed
+// number of seconds or days, are moved; others are returned unchanged.
+func (rrule RRule) fastForward(t time.Time) RRule {
+	if rrule.Dtstart.IsZero() || rrule.Count != 0 || !rrule.IsSimple() || !t.After(rrule.Dtstart) {
+		return rrule
+	}
+
+	interval := 1
+	if rrule.Interval != 0 {
+		interval = rrule.Interval
+	}
+
+	switch rrule.Frequency {
+	case Secondly, Minutely, Hourly:
+		step := time.Duration(interval) * time.Second
+		if rrule.Frequency == Minutely {
+			step *= 60
+		} else if rrule.Frequency == Hourly {
+			step *= 60 * 60
+		}
+		rrule.Dtstart = rrule.Dtstart.Add(t.Sub(rrule.Dtstart) / step * step)
+	case Daily, Weekly:
+		days := interval
+		if rrule.Frequency == Weekly {
+			days *= 7
+		}
+		// stop a period short, since the time of day may not have passed yet
+		periods := daysBetween(rrule.Dtstart, t)/days - 1
+		// skipping ahead to a day where daylight saving transitions skip the
+		// time of Dtstart would lose its wall clock, so stop short of it
+		for ; periods > 0; periods-- {
+			dtstart := rrule.Dtstart.AddDate(0, 0, periods*days)
+			if floating(dtstart).Equal(floating(rrule.Dtstart).AddDate(0, 0, periods*days)) {
+				rrule.Dtstart = dtstart
+				break
+			}
+		}
+	}
+
+	return rrule
+}
+
+// AllWithinHours returns instances of the pattern up to a limited number, like
+// AllFunc, but drops any instance whose local time of day is outside
+// [start, end), where start and end are offsets from midnight. For example,
+// a pattern every 30 minutes limited to 9*time.Hour and 17*time.Hour keeps
+// only 9:00 through 16:30.
+//
+// Unlike BYHOUR and BYMINUTE, which expand each period into the listed times,
+// this only filters, so it's best paired with a frequency fine enough to fall
+// within the hours. When both are present, an instance must satisfy each. The
+// time of day is read from the wall clock, so it's unaffected by daylight
+// savings transitions.
+func (rrule RRule) AllWithinHours(limit int, start, end time.Duration) []time.Time {
+	return rrule.AllFunc(limit, func(t time.Time) bool {
+		h, m, s := t.Clock()
+		tod := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second + time.Duration(t.Nanosecond())
+		return tod < start || tod >= end
+	})
+}
+
+// NextDistinctPeriods returns the first instance of the pattern after now in
+// each of the next n periods that have any, where unit sets the length of
+// the periods, such as Monthly for calendar months. Weeks begin on the
+// pattern's WKST. Fewer than n instances are returned if the pattern ends
+// first.
+func (rrule RRule) NextDistinctPeriods(now time.Time, n int, unit Frequency) []time.Time {
+	if n <= 0 {
+		return nil
+	}
+
+	weekStart := rrule.weekStart()
+	it := rrule.fastForward(now).Iterator()
+
+	var last time.Time
+	distinct := make([]time.Time, 0, n)
+	for len(distinct) < n {
+		next := it.Next()
+		if next == nil {
+			break
+		}
+		if !next.After(now) {
+			continue
+		}
+
+		period := periodStart(*next, unit, weekStart)
+		if len(distinct) > 0 && period.Equal(last) {
+			continue
+		}
+		last = period
+		distinct = append(distinct, *next)
+	}
+	return distinct
+}
+
+// periodStart returns the beginning of the period of length unit that
+// contains t, in t's location.
+func periodStart(t time.Time, unit Frequency, weekStart time.Weekday) time.Time {
+	y, mo, d := t.Date()
+	h, mi, s := t.Clock()
+
+	switch unit {
+	case Yearly:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, t.Location())
+	case Monthly:
+		return time.Date(y, mo, 1, 0, 0, 0, 0, t.Location())
+	case Weekly:
+		return backToWeekday(time.Date(y, mo, d, 0, 0, 0, 0, t.Location()), weekStart)
+	case Daily:
+		return time.Date(y, mo, d, 0, 0, 0, 0, t.Location())
+	case Hourly:
+		return time.Date(y, mo, d, h, 0, 0, 0, t.Location())
+	case Minutely:
+		return time.Date(y, mo, d, h, mi, 0, 0, t.Location())
+	default:
+		return time.Date(y, mo, d, h, mi, s, 0, t.Location())
+	}
+}
+
+// SameOccurrencesAs reports whether the pattern and other have the same
+// instances up to and including within, however they're expressed. For
+// example, FREQ=WEEKLY and FREQ=DAILY;INTERVAL=7 are the same from the same
+// Dtstart. Instances are compared as they're generated, stopping at the first
+// difference.
+func (rrule RRule) SameOccurrencesAs(other RRule, within time.Time) bool {
+	a, b := rrule.Iterator(), other.Iterator()
+
+	for {
+		na, nb := a.Next(), b.Next()
+		if na != nil && na.After(within) {
+			na = nil
+		}
+		if nb != nil && nb.After(within) {
+			nb = nil
+		}
+
+		switch {
+		case na == nil && nb == nil:
+			return true
+		case na == nil || nb == nil:
+			return false
+		case !na.Equal(*nb):
+			return false
+		}
+	}
+}
+
+// OffsetOccurrence is an instance of a pattern along with its offset from
+// UTC.
+type OffsetOccurrence struct {
+	Time time.Time
+
+	// Offset is the offset of Time from UTC in seconds, as returned by
+	// Time.Zone. A change between consecutive instances means a daylight
+	// savings transition lies between them.
+	Offset int
+}
+
+// AllWithOffset returns instances of the pattern up to a limited number, like
+// All, each with its offset from UTC.
+func (rrule RRule) AllWithOffset(limit int) []OffsetOccurrence {
+	all := rrule.All(limit)
+
+	occurrences := make([]OffsetOccurrence, len(all))
+	for i, t := range all {
+		_, offset := t.Zone()
+		occurrences[i] = OffsetOccurrence{Time: t, Offset: offset}
+	}
+	return occurrences
+}
+
+// Between returns the instances of the pattern within [after, before), or
+// [after, before] if inc is true. Iteration stops at the first instance past
+// before, so infinite patterns can be used, and simple patterns skip directly
+// to after rather than stepping through every earlier instance.
+func (rrule RRule) Between(after, before time.Time, inc bool) []time.Time {
+	it := rrule.fastForward(after).Iterator()
+
+	var all []time.Time
+	for {
+		next := it.Next()
+		if next == nil || next.After(before) || (!inc && next.Equal(before)) {
+			break
+		}
+		if next.Before(after) {
+			continue
+		}
+		all = append(all, *next)
+	}
+	return all
+}
+
+// Grid returns the instances of the pattern on each of the given number of
+// consecutive days beginning at start, as a calendar view displays them.
+// Each day begins at the same wall clock time as start, normally midnight, in
+// start's location, so days are 23 or 25 hours long across daylight savings
+// transitions.
+func (rrule RRule) Grid(start time.Time, days int) [][]time.Time {
+	if days <= 0 {
+		return nil
+	}
+
+	grid := make([][]time.Time, days)
+	day, end := 0, start.AddDate(0, 0, 1)
+	for _, t := range rrule.Between(start, start.AddDate(0, 0, days), false) {
+		for !t.Before(end) {
+			day++
+			end = start.AddDate(0, 0, day+1)
+		}
+		grid[day] = append(grid[day], t)
+	}
+	return grid
+}
+
+// After returns the first instance of the pattern after t, or at t if inc is
+// true. The boolean is false if the pattern has no such instance.
+func (rrule RRule) After(t time.Time, inc bool) (time.Time, bool) {
+	it := rrule.fastForward(t).Iterator()
+
+	for {
+		next := it.Next()
+		if next == nil {
+			return time.Time{}, false
+		}
+		if next.After(t) || (inc && next.Equal(t)) {
+			return *next, true
+		}
+	}
+}
+
+// Before returns the last instance of the pattern before t, or at t if inc is
+// true. The boolean is false if the pattern has no such instance, as when
+// Dtstart is after t. Instances are generated from Dtstart up to t, so this
+// works for infinite patterns too.
+func (rrule RRule) Before(t time.Time, inc bool) (time.Time, bool) {
+	// skip ahead no further than the last instance that may be returned
+	limit := t
+	if !inc {
+		limit = t.Add(-time.Nanosecond)
+	}
+	it := rrule.fastForward(limit).Iterator()
+
+	var last *time.Time
+	for {
+		next := it.Next()
+		if next == nil || next.After(t) || (!inc && next.Equal(t)) {
+			break
+		}
+		last = next
+	}
+
+	if last == nil {
+		return time.Time{}, false
+	}
+	return *last, true
+}
+
+// Contains reports whether t is an instance of the pattern. As with
+// time.Time.Equal, t matches an instance at the same instant in any
+// location, but must match it to the nanosecond.
+func (rrule RRule) Contains(t time.Time) bool {
+	next, ok := rrule.After(t, true)
+	return ok && next.Equal(t)
+}
@@ -0,0 +1,449 @@
+package rrule
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountUntil(t *testing.T) {
+	start := now.Truncate(time.Second)
+	rrule := RRule{Frequency: Daily, Dtstart: start}
+
+	assert.Equal(t, 0, rrule.CountUntil(start.Add(-time.Second)))
+	assert.Equal(t, 1, rrule.CountUntil(start))
+	assert.Equal(t, 5, rrule.CountUntil(time.Date(2018, 8, 29, 9, 8, 7, 0, time.UTC)))
+	assert.Equal(t, 5, rrule.CountUntil(time.Date(2018, 8, 30, 0, 0, 0, 0, time.UTC)))
+
+	counted := rrule.WithCount(rrule.CountUntil(time.Date(2018, 8, 30, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, uint64(5), counted.Count)
+	assert.Equal(t,
+		rfcAll(All(RRule{Frequency: Daily, Dtstart: start, Until: time.Date(2018, 8, 30, 0, 0, 0, 0, time.UTC)}.Iterator(), 0)),
+		rfcAll(All(counted.Iterator(), 0)),
+	)
+}
+
+func TestCountRemaining(t *testing.T) {
+	start := now.Truncate(time.Second)
+
+	counted := RRule{Frequency: Daily, Dtstart: start, Count: 5}
+	remaining, ok := counted.CountRemaining(start.Add(-time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, 5, remaining)
+	remaining, ok = counted.CountRemaining(start.AddDate(0, 0, 2))
+	assert.True(t, ok)
+	assert.Equal(t, 2, remaining)
+	remaining, ok = counted.CountRemaining(start.AddDate(1, 0, 0))
+	assert.True(t, ok)
+	assert.Equal(t, 0, remaining)
+
+	until := RRule{Frequency: Daily, Dtstart: start, Until: start.AddDate(1, 0, 0)}
+	remaining, ok = until.CountRemaining(start.AddDate(0, 0, 362))
+	assert.True(t, ok)
+	assert.Equal(t, 3, remaining)
+
+	_, ok = RRule{Frequency: Daily, Dtstart: start}.CountRemaining(start)
+	assert.False(t, ok)
+}
+
+func TestFirst(t *testing.T) {
+	// Dtstart is a Saturday, so it's only the first occurrence when it matches
+	monthly := RRule{Frequency: Monthly, Count: 3, Dtstart: now, ByWeekdays: []QualifiedWeekday{{N: 1, WD: time.Tuesday}}}
+	first, ok := monthly.First()
+	assert.True(t, ok)
+	assert.Equal(t, "2018-09-04T09:08:07Z", first.Format(time.RFC3339))
+
+	weekly := RRule{Frequency: Weekly, Dtstart: now, ByWeekdays: []QualifiedWeekday{{WD: time.Saturday}, {WD: time.Tuesday}}}
+	first, ok = weekly.First()
+	assert.True(t, ok)
+	assert.Equal(t, now, first)
+
+	first, ok = RRule{Frequency: Daily, Dtstart: now}.First()
+	assert.True(t, ok)
+	assert.Equal(t, now, first)
+
+	_, ok = RRule{Frequency: Daily, Dtstart: now, Until: now.Add(-time.Hour)}.First()
+	assert.False(t, ok)
+}
+
+func TestGetNth(t *testing.T) {
+	start := now.Truncate(time.Second)
+	rrule := RRule{Frequency: Daily, Dtstart: start, Count: 5}
+
+	for n, want := range map[int]time.Time{
+		0:  start,
+		4:  start.AddDate(0, 0, 4),
+		-1: start.AddDate(0, 0, 4),
+		-5: start,
+	} {
+		got, ok := rrule.GetNth(n)
+		assert.True(t, ok, n)
+		assert.Equal(t, want, got, n)
+	}
+
+	for _, n := range []int{5, -6, math.MinInt} {
+		_, ok := rrule.GetNth(n)
+		assert.False(t, ok, n)
+	}
+
+	infinite := RRule{Frequency: Weekly, Dtstart: start}
+	tenth, ok := infinite.GetNth(9)
+	assert.True(t, ok)
+	assert.Equal(t, start.AddDate(0, 0, 63), tenth)
+	_, ok = infinite.GetNth(-1)
+	assert.False(t, ok)
+}
+
+func TestToCountToUntil(t *testing.T) {
+	start := now.Truncate(time.Second)
+
+	untilRule := RRule{Frequency: Weekly, Dtstart: start, Until: time.Date(2018, 9, 30, 0, 0, 0, 0, time.UTC)}
+	counted, ok := untilRule.ToCount()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(6), counted.Count)
+	assert.True(t, counted.Until.IsZero())
+	assert.Equal(t, rfcAll(All(untilRule.Iterator(), 0)), rfcAll(All(counted.Iterator(), 0)))
+
+	untiled, ok := counted.ToUntil()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), untiled.Count)
+	assert.Equal(t, "2018-09-29T09:08:07Z", untiled.Until.Format(time.RFC3339))
+	assert.Equal(t, rfcAll(All(counted.Iterator(), 0)), rfcAll(All(untiled.Iterator(), 0)))
+
+	_, ok = RRule{Frequency: Daily, Dtstart: start}.ToCount()
+	assert.False(t, ok)
+	_, ok = RRule{Frequency: Daily, Dtstart: start}.ToUntil()
+	assert.False(t, ok)
+}
+
+func TestAllWithReason(t *testing.T) {
+	start := now.Truncate(time.Second)
+
+	counted := RRule{Frequency: Daily, Count: 3, Dtstart: start}
+	all, reason := counted.AllWithReason(0)
+	assert.Len(t, all, 3)
+	assert.Equal(t, ByCount, reason)
+
+	all, reason = counted.AllWithReason(3)
+	assert.Len(t, all, 3)
+	assert.Equal(t, ByCount, reason)
+
+	all, reason = counted.AllWithReason(2)
+	assert.Len(t, all, 2)
+	assert.Equal(t, ByLimit, reason)
+
+	until := RRule{Frequency: Daily, Until: time.Date(2018, 8, 30, 0, 0, 0, 0, time.UTC), Dtstart: start}
+	all, reason = until.AllWithReason(0)
+	assert.Len(t, all, 5)
+	assert.Equal(t, ByUntil, reason)
+
+	endOfTime := RRule{Frequency: Yearly, Dtstart: time.Date(219248495, time.December, 7, 0, 0, 0, 0, time.UTC)}
+	all, reason = endOfTime.AllWithReason(10)
+	assert.Len(t, all, 4)
+	assert.Equal(t, Exhausted, reason)
+
+	// without a limit, an infinite pattern stops at DefaultHorizon
+	yearly := RRule{Frequency: Yearly, Dtstart: time.Date(2098, 7, 4, 0, 0, 0, 0, time.UTC)}
+	all, reason = yearly.AllWithReason(0)
+	assert.Equal(t, []string{"2098-07-04T00:00:00Z", "2099-07-04T00:00:00Z"}, rfcAll(all))
+	assert.Equal(t, ByLimit, reason)
+}
+
+func TestNextNFrom(t *testing.T) {
+	start := now.Truncate(time.Second)
+	from := time.Date(2018, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	infinite := RRule{Frequency: Daily, Dtstart: start}
+	assert.Equal(t, []string{"2018-09-01T09:08:07Z", "2018-09-02T09:08:07Z", "2018-09-03T09:08:07Z"}, rfcAll(infinite.NextNFrom(from, 3)))
+	assert.Equal(t, []string{"2018-09-01T09:08:07Z"}, rfcAll(infinite.NextNFrom(time.Date(2018, 9, 1, 9, 8, 7, 0, time.UTC), 1)))
+
+	// COUNT=10 counts from Dtstart, so only the 10th instance, on
+	// 2018-09-03, and those before it remain.
+	counted := RRule{Frequency: Daily, Count: 10, Dtstart: start}
+	assert.Equal(t, []string{"2018-09-01T09:08:07Z", "2018-09-02T09:08:07Z", "2018-09-03T09:08:07Z"}, rfcAll(counted.NextNFrom(from, 5)))
+}
+
+func TestAllFunc(t *testing.T) {
+	start := now.Truncate(time.Second) // a saturday
+	weekend := func(t time.Time) bool {
+		return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+	}
+
+	infinite := RRule{Frequency: Daily, Dtstart: start}
+	assert.Equal(t, []string{"2018-08-27T09:08:07Z", "2018-08-28T09:08:07Z", "2018-08-29T09:08:07Z"}, rfcAll(infinite.AllFunc(3, weekend)))
+
+	// COUNT includes the skipped instances
+	counted := RRule{Frequency: Daily, Count: 4, Dtstart: start}
+	assert.Equal(t, []string{"2018-08-27T09:08:07Z", "2018-08-28T09:08:07Z"}, rfcAll(counted.AllFunc(0, weekend)))
+
+	// without a limit, an infinite pattern stops at DefaultHorizon
+	yearly := RRule{Frequency: Yearly, Dtstart: time.Date(2098, 7, 4, 0, 0, 0, 0, time.UTC)}
+	assert.Equal(t, []string{"2098-07-04T00:00:00Z"}, rfcAll(yearly.AllFunc(0, weekend)))
+}
+
+func TestUpcoming(t *testing.T) {
+	start := now.Truncate(time.Second)
+	every15 := RRule{Frequency: Minutely, Interval: 15, Dtstart: start}
+
+	// now itself is excluded and the end of the horizon is included
+	assert.Equal(t, []string{"2018-08-25T09:23:07Z", "2018-08-25T09:38:07Z", "2018-08-25T09:53:07Z", "2018-08-25T10:08:07Z"}, rfcAll(every15.Upcoming(start, time.Hour)))
+
+	// consecutive windows never fire the boundary instance twice
+	var polled []time.Time
+	window := start.Add(-time.Second)
+	for i := 0; i < 2; i++ {
+		polled = append(polled, every15.Upcoming(window, 30*time.Minute)...)
+		window = window.Add(30 * time.Minute)
+	}
+	assert.Equal(t, []string{"2018-08-25T09:08:07Z", "2018-08-25T09:23:07Z", "2018-08-25T09:38:07Z", "2018-08-25T09:53:07Z"}, rfcAll(polled))
+
+	assert.Empty(t, every15.Upcoming(start.Add(time.Second), time.Minute))
+
+	// a window long after DTSTART
+	assert.Equal(t, []string{"2019-08-25T09:23:07Z", "2019-08-25T09:38:07Z"}, rfcAll(every15.Upcoming(start.AddDate(1, 0, 0), 30*time.Minute)))
+}
+
+func TestFillRing(t *testing.T) {
+	start := now.Truncate(time.Second)
+	every5 := RRule{Frequency: Minutely, Interval: 5, Dtstart: start}
+
+	ring := every5.FillRing(start, 3)
+	assert.Equal(t, []string{"2018-08-25T09:13:07Z", "2018-08-25T09:18:07Z", "2018-08-25T09:23:07Z"}, rfcAll(ring))
+	assert.Equal(t, 3, cap(ring))
+
+	// refilling from the last fire time continues without repeats
+	assert.Equal(t, []string{"2018-08-25T09:28:07Z", "2018-08-25T09:33:07Z"}, rfcAll(every5.FillRing(ring[2], 2)))
+
+	counted := RRule{Frequency: Minutely, Interval: 5, Count: 3, Dtstart: start}
+	assert.Equal(t, []string{"2018-08-25T09:13:07Z", "2018-08-25T09:18:07Z"}, rfcAll(counted.FillRing(start, 5)))
+	assert.Empty(t, every5.FillRing(start, 0))
+}
+
+func BenchmarkFillRing(b *testing.B) {
+	start := now.Truncate(time.Second)
+	rrules := map[string]RRule{
+		"simple":  {Frequency: Minutely, Interval: 5, Dtstart: start},
+		"by hour": {Frequency: Daily, ByHours: []int{9, 12, 17}, Dtstart: start},
+	}
+	from := start.AddDate(0, 0, 30)
+
+	for name, rrule := range rrules {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				rrule.FillRing(from, 16)
+			}
+		})
+	}
+}
+
+func TestFastForward(t *testing.T) {
+	start := now.Truncate(time.Second)
+	from := start.AddDate(0, 3, 0).Add(time.Hour)
+
+	for _, rrule := range []RRule{
+		{Frequency: Secondly, Interval: 7, Dtstart: start},
+		{Frequency: Minutely, Interval: 5, Dtstart: start},
+		{Frequency: Hourly, Interval: 5, Dtstart: time.Date(2018, 11, 3, 1, 30, 0, 0, NewYork())},
+		{Frequency: Daily, Interval: 3, Dtstart: time.Date(2018, 11, 3, 1, 30, 0, 0, NewYork())},
+		{Frequency: Weekly, Interval: 2, Dtstart: start},
+		{Frequency: Monthly, Dtstart: start},
+	} {
+		t.Run(rrule.String(), func(t *testing.T) {
+			assert.Equal(t, rrule.NextNFrom(from, 5), rrule.fastForward(from).NextNFrom(from, 5))
+		})
+	}
+}
+
+func TestAllWithinHours(t *testing.T) {
+	start := time.Date(2018, 8, 24, 16, 0, 0, 0, NewYork())
+
+	every30 := RRule{Frequency: Minutely, Interval: 30, Dtstart: start}
+	assert.Equal(t, []string{
+		"2018-08-24T16:00:00-04:00", "2018-08-24T16:30:00-04:00",
+		"2018-08-25T09:00:00-04:00", "2018-08-25T09:30:00-04:00",
+	}, rfcAll(every30.AllWithinHours(4, 9*time.Hour, 17*time.Hour)))
+
+	// minute boundaries aren't limited to whole hours
+	assert.Equal(t, []string{"2018-08-24T16:30:00-04:00", "2018-08-25T16:30:00-04:00"}, rfcAll(every30.AllWithinHours(2, 16*time.Hour+15*time.Minute, 16*time.Hour+45*time.Minute)))
+
+	// with BYHOUR, both must hold
+	byHour := RRule{Frequency: Daily, ByHours: []int{8, 12, 18}, Dtstart: start}
+	assert.Equal(t, []string{"2018-08-25T12:00:00-04:00", "2018-08-26T12:00:00-04:00"}, rfcAll(byHour.AllWithinHours(2, 9*time.Hour, 17*time.Hour)))
+
+	// without a limit, an infinite pattern stops at DefaultHorizon
+	yearly := RRule{Frequency: Yearly, Dtstart: time.Date(2098, 7, 4, 9, 0, 0, 0, time.UTC)}
+	assert.Equal(t, []string{"2098-07-04T09:00:00Z", "2099-07-04T09:00:00Z"}, rfcAll(yearly.AllWithinHours(0, 9*time.Hour, 17*time.Hour)))
+}
+
+func TestNextDistinctPeriods(t *testing.T) {
+	start := now.Truncate(time.Second) // a saturday
+
+	// the 1st and 15th of each month, from September
+	twiceMonthly := RRule{Frequency: Monthly, ByMonthDays: []int{1, 15}, Dtstart: start}
+	assert.Equal(t, []string{"2018-09-01T09:08:07Z", "2018-10-01T09:08:07Z", "2018-11-01T09:08:07Z"}, rfcAll(twiceMonthly.NextDistinctPeriods(start, 3, Monthly)))
+
+	// only after now, so September 1st itself is skipped
+	assert.Equal(t, []string{"2018-09-15T09:08:07Z", "2018-10-01T09:08:07Z"}, rfcAll(twiceMonthly.NextDistinctPeriods(time.Date(2018, 9, 1, 9, 8, 7, 0, time.UTC), 2, Monthly)))
+
+	// weeks begin on WKST: with the default of monday, each saturday is
+	// followed by a sunday of the same week, while with WKST=SU, each sunday
+	// is followed by a saturday of the same week.
+	weekend := RRule{Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{WD: time.Saturday}, {WD: time.Sunday}}, Dtstart: start}
+	assert.Equal(t, []string{"2018-08-26T09:08:07Z", "2018-09-01T09:08:07Z"}, rfcAll(weekend.NextDistinctPeriods(start, 2, Weekly)))
+
+	sunday := time.Sunday
+	weekend.WeekStart = &sunday
+	assert.Equal(t, []string{"2018-08-26T09:08:07Z", "2018-09-02T09:08:07Z", "2018-09-09T09:08:07Z"}, rfcAll(weekend.NextDistinctPeriods(start, 3, Weekly)))
+
+	counted := RRule{Frequency: Daily, Count: 40, Dtstart: start}
+	assert.Equal(t, []string{"2018-08-26T09:08:07Z", "2018-09-01T09:08:07Z", "2018-10-01T09:08:07Z"}, rfcAll(counted.NextDistinctPeriods(start, 6, Monthly)))
+
+	// long after DTSTART
+	daily := RRule{Frequency: Daily, Dtstart: start}
+	assert.Equal(t, []string{"2020-08-26T09:08:07Z", "2020-09-01T09:08:07Z"}, rfcAll(daily.NextDistinctPeriods(start.AddDate(2, 0, 0), 2, Monthly)))
+}
+
+func TestSameOccurrencesAs(t *testing.T) {
+	start := now.Truncate(time.Second)
+	within := start.AddDate(1, 0, 0)
+
+	weekly := RRule{Frequency: Weekly, Interval: 1, Dtstart: start}
+	assert.True(t, weekly.SameOccurrencesAs(RRule{Frequency: Daily, Interval: 7, Dtstart: start}, within))
+	assert.True(t, weekly.SameOccurrencesAs(RRule{Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{WD: time.Saturday}}, Dtstart: start}, within))
+	assert.False(t, weekly.SameOccurrencesAs(RRule{Frequency: Daily, Interval: 7, Dtstart: start.Add(time.Hour)}, within))
+
+	// a difference after the horizon doesn't count
+	counted := RRule{Frequency: Weekly, Count: 3, Dtstart: start}
+	assert.True(t, weekly.SameOccurrencesAs(counted, start.AddDate(0, 0, 14)))
+	assert.False(t, weekly.SameOccurrencesAs(counted, start.AddDate(0, 0, 21)))
+
+	// the same instant in different locations is the same instance
+	assert.True(t, weekly.SameOccurrencesAs(RRule{Frequency: Weekly, Dtstart: start.In(time.FixedZone("", 2*60*60))}, within))
+}
+
+func TestAllWithOffset(t *testing.T) {
+	// New York falls back on 2018-11-04
+	weekly := RRule{Frequency: Weekly, Count: 3, Dtstart: time.Date(2018, 10, 28, 9, 0, 0, 0, NewYork())}
+
+	occurrences := weekly.AllWithOffset(0)
+	require.Len(t, occurrences, 3)
+
+	var offsets []int
+	for _, o := range occurrences {
+		offsets = append(offsets, o.Offset)
+	}
+	assert.Equal(t, []int{-4 * 60 * 60, -5 * 60 * 60, -5 * 60 * 60}, offsets)
+	assert.Equal(t, "2018-11-04T09:00:00-05:00", occurrences[1].Time.Format(time.RFC3339))
+
+	// without a limit, an infinite pattern stops at DefaultHorizon
+	yearly := RRule{Frequency: Yearly, Dtstart: time.Date(2098, 7, 4, 0, 0, 0, 0, time.UTC)}
+	assert.Len(t, yearly.AllWithOffset(0), 2)
+}
+
+func TestGrid(t *testing.T) {
+	// New York falls back on sunday, 2018-11-04, making it 25 hours long
+	start := time.Date(2018, 11, 3, 0, 0, 0, 0, NewYork())
+	hourly := RRule{Frequency: Hourly, Interval: 8, Dtstart: start.Add(-2 * time.Hour)}
+
+	grid := hourly.Grid(start, 3)
+	require.Len(t, grid, 3)
+	assert.Equal(t, []string{"2018-11-03T06:00:00-04:00", "2018-11-03T14:00:00-04:00", "2018-11-03T22:00:00-04:00"}, rfcAll(grid[0]))
+	assert.Equal(t, []string{"2018-11-04T05:00:00-05:00", "2018-11-04T13:00:00-05:00", "2018-11-04T21:00:00-05:00"}, rfcAll(grid[1]))
+	assert.Equal(t, []string{"2018-11-05T05:00:00-05:00", "2018-11-05T13:00:00-05:00", "2018-11-05T21:00:00-05:00"}, rfcAll(grid[2]))
+
+	// days without instances are empty
+	weekly := RRule{Frequency: Weekly, Dtstart: start.Add(9 * time.Hour)}
+	grid = weekly.Grid(start.AddDate(0, 0, -1), 3)
+	assert.Equal(t, [][]time.Time{nil, {start.Add(9 * time.Hour)}, nil}, grid)
+}
+
+func TestBetween(t *testing.T) {
+	start := now.Truncate(time.Second)
+	daily := RRule{Frequency: Daily, Dtstart: start}
+
+	after := time.Date(2019, 8, 25, 9, 8, 7, 0, time.UTC)
+	before := after.AddDate(0, 0, 2)
+	assert.Equal(t, []string{"2019-08-25T09:08:07Z", "2019-08-26T09:08:07Z"}, rfcAll(daily.Between(after, before, false)))
+	assert.Equal(t, []string{"2019-08-25T09:08:07Z", "2019-08-26T09:08:07Z", "2019-08-27T09:08:07Z"}, rfcAll(daily.Between(after, before, true)))
+
+	byDay := RRule{Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Thursday}}, Dtstart: start}
+	assert.Equal(t, []string{"2019-08-26T09:08:07Z", "2019-08-29T09:08:07Z"}, rfcAll(byDay.Between(after, after.AddDate(0, 0, 7), false)))
+
+	// a window entirely before Dtstart is empty
+	assert.Empty(t, daily.Between(start.AddDate(-1, 0, 0), start.AddDate(0, 0, -1), true))
+}
+
+func BenchmarkBetween(b *testing.B) {
+	daily := RRule{Frequency: Daily, Dtstart: now}
+	after := now.AddDate(20, 0, 0)
+
+	for i := 0; i < b.N; i++ {
+		daily.Between(after, after.AddDate(0, 0, 7), false)
+	}
+}
+
+func TestAfter(t *testing.T) {
+	start := now.Truncate(time.Second)
+	daily := RRule{Frequency: Daily, Dtstart: start}
+
+	at := time.Date(2019, 8, 25, 9, 8, 7, 0, time.UTC)
+	next, ok := daily.After(at, true)
+	assert.True(t, ok)
+	assert.Equal(t, "2019-08-25T09:08:07Z", next.Format(time.RFC3339))
+
+	next, ok = daily.After(at, false)
+	assert.True(t, ok)
+	assert.Equal(t, "2019-08-26T09:08:07Z", next.Format(time.RFC3339))
+
+	next, ok = daily.After(start.AddDate(-1, 0, 0), false)
+	assert.True(t, ok)
+	assert.Equal(t, start, next)
+
+	_, ok = daily.WithCount(3).After(at, true)
+	assert.False(t, ok)
+}
+
+func TestBefore(t *testing.T) {
+	start := now.Truncate(time.Second)
+	daily := RRule{Frequency: Daily, Dtstart: start}
+
+	at := time.Date(2019, 8, 25, 9, 8, 7, 0, time.UTC)
+	prev, ok := daily.Before(at, true)
+	assert.True(t, ok)
+	assert.Equal(t, "2019-08-25T09:08:07Z", prev.Format(time.RFC3339))
+
+	prev, ok = daily.Before(at, false)
+	assert.True(t, ok)
+	assert.Equal(t, "2019-08-24T09:08:07Z", prev.Format(time.RFC3339))
+
+	// a finite pattern's last instance
+	prev, ok = daily.WithCount(3).Before(at, false)
+	assert.True(t, ok)
+	assert.Equal(t, "2018-08-27T09:08:07Z", prev.Format(time.RFC3339))
+
+	minutely := RRule{Frequency: Minutely, Dtstart: start}
+	prev, ok = minutely.Before(at, false)
+	assert.True(t, ok)
+	assert.Equal(t, "2019-08-25T09:07:07Z", prev.Format(time.RFC3339))
+
+	_, ok = daily.Before(start, false)
+	assert.False(t, ok)
+	_, ok = daily.Before(start.AddDate(0, 0, -1), true)
+	assert.False(t, ok)
+}
+
+func TestContains(t *testing.T) {
+	start := time.Date(2018, 8, 25, 9, 0, 0, 0, NewYork())
+	weekly := RRule{Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Friday}}, Dtstart: start}
+
+	assert.True(t, weekly.Contains(time.Date(2019, 8, 26, 9, 0, 0, 0, NewYork())))
+	assert.True(t, weekly.Contains(time.Date(2019, 8, 26, 13, 0, 0, 0, time.UTC)), "the same instant in another location")
+	assert.False(t, weekly.Contains(time.Date(2019, 8, 26, 9, 0, 0, 1, NewYork())))
+	assert.False(t, weekly.Contains(time.Date(2019, 8, 27, 9, 0, 0, 0, NewYork())))
+	assert.False(t, weekly.Contains(time.Date(2018, 8, 24, 9, 0, 0, 0, NewYork())), "before Dtstart")
+	assert.False(t, weekly.WithCount(2).Contains(time.Date(2019, 8, 26, 9, 0, 0, 0, NewYork())))
+}
@@ -1,6 +1,9 @@
 package rrule
 
 import (
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
@@ -21,14 +24,28 @@ type Recurrence struct {
 	// detail.
 	FloatingLocation bool
 
-	// Patterns and instances to include. Repeated instances are included only
-	// once, even if defined by multiple patterns.
+	// DateValued is true when Dtstart is a date rather than a date-time, as
+	// for all-day events (DTSTART;VALUE=DATE). RDates are then dates as
+	// well. Date values are always floating.
+	DateValued bool
+
+	// Patterns and instances to include. Their instances are merged in
+	// order, and repeated instances, at exactly the same instant, are
+	// included only once, even if defined by multiple patterns. RFC 5545
+	// recommends against more than one RRULE, but they're common in
+	// practice, so any number are supported.
 	//
 	// The Dtstart property of RRule and ExRule patterns are
 	// ignored, including when the above Dtstart property is zero.
 	RRules []RRule
 	RDates []time.Time
 
+	// RPeriods holds the RDATE values given as periods (RDATE;VALUE=PERIOD),
+	// each with its own end. The start of each is also in RDates, and
+	// Events ends an instance with that start at the end of its period
+	// rather than after Duration.
+	RPeriods []Period
+
 	// Patterns and instances to exclude. These take precedence over the
 	// inclusions. Note: this feature was deprecated in RFC5545, noting its
 	// limited (and buggy) adoption and real-world use case. It is
@@ -36,16 +53,101 @@ type Recurrence struct {
 	// compatibility.
 	ExRules []RRule
 	ExDates []time.Time
+
+	// ExDays excludes every instance that falls on the same calendar day as
+	// one of its entries, regardless of time of day. These come from
+	// date-valued exclusions (EXDATE;VALUE=DATE), whereas ExDates match only
+	// the exact instant.
+	ExDays []time.Time
+
+	// Duration is the length of each instance, used by Events, after
+	// DurationDays. Zero means instances are instantaneous. It's the hours,
+	// minutes, and seconds of DURATION, or computed from DTEND, and is an
+	// exact length of time, so an instance spanning a daylight saving
+	// transition ends at a different wall clock time than one that doesn't.
+	Duration time.Duration
+
+	// DurationDays is the days and weeks of DURATION, as a number of days.
+	// These are nominal, so Events adds them on the calendar: an instance
+	// lasting P1D ends at the same wall clock time the next day, even across
+	// a daylight saving transition.
+	DurationDays int
+
+	// Dtend is the end of the first instance, when parsed from DTEND. Each
+	// instance then ends at the same wall clock time, and on the same day
+	// relative to its start, as the first, rather than after Duration.
+	Dtend time.Time
+
+	// Extras holds the properties that aren't part of the recurrence, such as
+	// SUMMARY or X-WR-TIMEZONE, in the order they were parsed. String writes
+	// them back after the recurrence's own properties.
+	Extras []Property
+
+	// Raw holds each property line the recurrence was parsed from, in order
+	// and after unfolding, including properties that were ignored. It's nil
+	// for recurrences constructed in code. The Raw of each parsed RRule holds
+	// just its value. See RawString.
+	Raw []string
+}
+
+// Period is a span of time, such as a single instance of a recurrence that
+// has a Duration.
+type Period struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Property is a content line that a recurrence carries without interpreting.
+type Property struct {
+	Name string `json:"name"`
+
+	// Params holds the parameters of the property as written, without the
+	// leading semicolon, such as "LANGUAGE=en", or is empty.
+	Params string `json:"params,omitempty"`
+
+	Value string `json:"value"`
+
+	// Standard is true for properties defined by RFC 5545 that are ignored
+	// here, such as SUMMARY, and false for extensions, such as those named
+	// with an X- prefix, and properties this package doesn't know.
+	Standard bool `json:"standard,omitempty"`
+}
+
+// String returns the property as a content line.
+func (p Property) String() string {
+	if p.Params == "" {
+		return p.Name + ":" + p.Value
+	}
+	return p.Name + ";" + p.Params + ":" + p.Value
 }
 
 // String returns the RFC 5545 representation of the recurrence, which is a
-// newline delimited format.
+// newline delimited format. Properties are always written in the same order:
+// DTSTART, then DTEND or DURATION, then each RRULE, EXRULE, RDATE, and EXDATE
+// in the order of their fields, and finally Extras, so equal recurrences
+// produce identical output. ParseRecurrence parses the output back into an
+// equivalent recurrence.
 func (r *Recurrence) String() string {
 	b := &strings.Builder{}
-	if !r.Dtstart.IsZero() {
+	if r.DateValued {
+		b.WriteString(formatDate("DTSTART", r.Dtstart))
+		b.WriteString("\n")
+	} else if !r.Dtstart.IsZero() {
 		b.WriteString(formatTime("DTSTART", r.Dtstart, r.FloatingLocation))
 		b.WriteString("\n")
 	}
+	switch {
+	case !r.Dtend.IsZero() && r.DateValued:
+		b.WriteString(formatDate("DTEND", r.Dtend))
+		b.WriteString("\n")
+	case !r.Dtend.IsZero():
+		b.WriteString(formatTime("DTEND", r.Dtend, r.FloatingLocation))
+		b.WriteString("\n")
+	case r.Duration != 0 || r.DurationDays != 0:
+		b.WriteString("DURATION:")
+		b.WriteString(formatDuration(r.DurationDays, r.Duration))
+		b.WriteString("\n")
+	}
 	for _, rrule := range r.RRules {
 		b.WriteString("RRULE:")
 		b.WriteString(rrule.String())
@@ -57,17 +159,234 @@ func (r *Recurrence) String() string {
 		b.WriteString("\n")
 	}
 	for _, rdate := range r.RDates {
-		b.WriteString(formatTime("RDATE", rdate, r.FloatingLocation))
+		if r.periodOf(rdate) >= 0 {
+			continue
+		}
+		if r.DateValued {
+			b.WriteString(formatDate("RDATE", rdate))
+		} else {
+			b.WriteString(formatTime("RDATE", rdate, r.FloatingLocation))
+		}
+		b.WriteString("\n")
+	}
+	for _, period := range r.RPeriods {
+		b.WriteString(formatPeriod("RDATE", period, r.FloatingLocation))
 		b.WriteString("\n")
 	}
 	for _, exdate := range r.ExDates {
 		b.WriteString(formatTime("EXDATE", exdate, r.FloatingLocation))
 		b.WriteString("\n")
 	}
+	for _, exday := range r.ExDays {
+		b.WriteString(formatDate("EXDATE", exday))
+		b.WriteString("\n")
+	}
+	for _, extra := range r.Extras {
+		b.WriteString(extra.String())
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// RawString returns the property lines the recurrence was parsed from, or
+// String if it was constructed in code.
+func (r *Recurrence) RawString() string {
+	if r.Raw == nil {
+		return r.String()
+	}
 
+	b := &strings.Builder{}
+	for _, line := range r.Raw {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
 	return b.String()
 }
 
+// Clone returns a copy of the recurrence that shares no memory with it, so
+// either can be changed without affecting the other. The patterns are copied
+// with RRule.Clone.
+func (r *Recurrence) Clone() *Recurrence {
+	clone := *r
+
+	cloneRRules := func(rrules []RRule) []RRule {
+		if rrules == nil {
+			return nil
+		}
+		out := make([]RRule, len(rrules))
+		for i, rrule := range rrules {
+			out[i] = rrule.Clone()
+		}
+		return out
+	}
+	clone.RRules = cloneRRules(r.RRules)
+	clone.ExRules = cloneRRules(r.ExRules)
+
+	clone.RDates = cloneTimes(r.RDates)
+	if r.RPeriods != nil {
+		clone.RPeriods = append([]Period{}, r.RPeriods...)
+	}
+	clone.ExDates = cloneTimes(r.ExDates)
+	clone.ExDays = cloneTimes(r.ExDays)
+	if r.Extras != nil {
+		clone.Extras = append([]Property{}, r.Extras...)
+	}
+	if r.Raw != nil {
+		clone.Raw = append([]string{}, r.Raw...)
+	}
+
+	return &clone
+}
+
+func cloneTimes(tt []time.Time) []time.Time {
+	if tt == nil {
+		return nil
+	}
+	return append([]time.Time{}, tt...)
+}
+
+// Shift returns a copy of the recurrence with every instance moved by d.
+// Dtstart, Dtend, the Until of each pattern, RDates, RPeriods, and ExDates
+// are shifted by d, and ExDays by the whole number of days in d.
+//
+// Patterns that pin the time of day with BYHOUR, BYMINUTE, or BYSECOND can't
+// be shifted this way, so Shift returns an error if any are present. A date
+// Until moves to the day its last instance is shifted to.
+func (r *Recurrence) Shift(d time.Duration) (*Recurrence, error) {
+	shifted := r.Clone()
+	shifted.Raw = nil
+
+	for _, rrules := range [][]RRule{shifted.RRules, shifted.ExRules} {
+		for i := range rrules {
+			rrule := &rrules[i]
+			if len(rrule.ByHours) > 0 || len(rrule.ByMinutes) > 0 || len(rrule.BySeconds) > 0 {
+				return nil, fmt.Errorf("rrule %q sets the time of day with BYHOUR, BYMINUTE, or BYSECOND and cannot be shifted", *rrule)
+			}
+			if rrule.UntilDate {
+				until, err := shiftUntilDate(*rrule, r.Dtstart, d)
+				if err != nil {
+					return nil, err
+				}
+				rrule.Until = until
+			} else if !rrule.Until.IsZero() {
+				rrule.Until = rrule.Until.Add(d)
+			}
+			rrule.Raw = ""
+		}
+	}
+
+	if !shifted.Dtstart.IsZero() {
+		shifted.Dtstart = shifted.Dtstart.Add(d)
+	}
+	if !shifted.Dtend.IsZero() {
+		shifted.Dtend = shifted.Dtend.Add(d)
+	}
+	for i := range shifted.RDates {
+		shifted.RDates[i] = shifted.RDates[i].Add(d)
+	}
+	for i := range shifted.RPeriods {
+		shifted.RPeriods[i] = Period{Start: shifted.RPeriods[i].Start.Add(d), End: shifted.RPeriods[i].End.Add(d)}
+	}
+	for i := range shifted.ExDates {
+		shifted.ExDates[i] = shifted.ExDates[i].Add(d)
+	}
+	for i := range shifted.ExDays {
+		shifted.ExDays[i] = shifted.ExDays[i].AddDate(0, 0, int(d/(24*time.Hour)))
+	}
+
+	return shifted, nil
+}
+
+// shiftUntilDate returns the date Until of rrule moved to the day that its
+// last instance is shifted to. Without BYHOUR, BYMINUTE, or BYSECOND, every
+// instance of a DAILY or less frequent pattern is at the time of day of
+// dtstart, so the last is at that time on the day of Until. The instances of
+// more frequent patterns fill the day, so they can only be shifted by whole
+// days.
+func shiftUntilDate(rrule RRule, dtstart time.Time, d time.Duration) (time.Time, error) {
+	until := rrule.Until
+	if rrule.Frequency < Daily {
+		if d%(24*time.Hour) != 0 {
+			return time.Time{}, fmt.Errorf("rrule %q ends on a date and can only be shifted by whole days", rrule)
+		}
+		return until.AddDate(0, 0, int(d/(24*time.Hour))), nil
+	}
+
+	loc := dtstart.Location()
+	last := time.Date(until.Year(), until.Month(), until.Day(), dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(), loc)
+	last = last.Add(d).In(loc)
+	return time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, until.Location()), nil
+}
+
+// Validate checks that each pattern of the recurrence is valid, as
+// RRule.Validate does. A date-valued recurrence's patterns must also not set
+// the time of day with BYHOUR, BYMINUTE, or BYSECOND, nor repeat more often
+// than daily, since its instances are whole days. Dtend must not be before
+// Dtstart, nor Duration negative.
+func (r *Recurrence) Validate() error {
+	return r.validate(rejectUntilBeforeStart)
+}
+
+// untilPolicy decides whether validation rejects a pattern whose Until is
+// before Dtstart.
+type untilPolicy int
+
+const (
+	rejectUntilBeforeStart untilPolicy = iota
+
+	// allowUntilBeforeStart accepts such a pattern, which simply produces no
+	// instances.
+	allowUntilBeforeStart
+)
+
+// validate checks the recurrence as Validate does, applying until to the
+// Until of each pattern.
+func (r *Recurrence) validate(until untilPolicy) error {
+	for _, rrules := range [][]RRule{r.RRules, r.ExRules} {
+		for _, rrule := range rrules {
+			rrule.Dtstart = r.Dtstart
+			validate := rrule.Validate
+			if until == allowUntilBeforeStart {
+				validate = rrule.validateParts
+			}
+			if err := validate(); err != nil {
+				return err
+			}
+
+			if !r.DateValued {
+				continue
+			}
+			if len(rrule.ByHours) > 0 || len(rrule.ByMinutes) > 0 || len(rrule.BySeconds) > 0 {
+				return errors.New("BYHOUR, BYMINUTE, and BYSECOND must not be used when DTSTART is a date")
+			}
+			if rrule.Frequency < Daily {
+				return fmt.Errorf("FREQ=%s must not be used when DTSTART is a date", rrule.Frequency)
+			}
+		}
+	}
+
+	if !r.Dtend.IsZero() && r.Dtend.Before(r.Dtstart) {
+		return errors.New("DTEND must not be before DTSTART")
+	}
+	if r.Duration < 0 || r.DurationDays < 0 {
+		return errors.New("DURATION must not be negative")
+	}
+
+	return nil
+}
+
+// Location returns the location the recurrence is evaluated in, which is the
+// location of Dtstart, or time.Local if Dtstart is zero. When
+// FloatingLocation is true the recurrence has no fixed zone, and the returned
+// location is only the one it was parsed or constructed with.
+func (r *Recurrence) Location() *time.Location {
+	if r.Dtstart.IsZero() {
+		return time.Local
+	}
+	return r.Dtstart.Location()
+}
+
 func (r *Recurrence) setDtstart() {
 	for i, rr := range r.RRules {
 		rr.Dtstart = r.Dtstart
@@ -97,17 +416,170 @@ func All(it Iterator, limit int) []time.Time {
 	return all
 }
 
-// Iterator returns an iterator for the recurrence.
+// All returns the instances of the recurrence up to a limited number: those
+// of RRules and RDates, less those of ExRules, ExDates, and ExDays, in order
+// and without duplicates. ExRules and ExDates exclude only instances at
+// exactly the same instant. The limit applies to the merged instances, after
+// exclusions, and each pattern is only expanded as far as needed to reach it,
+// so a limit bounds a recurrence of infinite patterns. If limit is 0, RRules
+// with neither COUNT nor UNTIL end at DefaultHorizon, and if there are any,
+// no more than DefaultLimit instances are returned, as with RRule.All.
+func (r Recurrence) All(limit int) []time.Time {
+	it, limit := r.boundedIterator(limit)
+	return All(it, limit)
+}
+
+// boundedIterator returns an Iterator for the recurrence along with the limit
+// to apply to it, like RRule.boundedIterator. If limit is 0, RRules with
+// neither COUNT nor UNTIL end at DefaultHorizon, and if there are any, the
+// limit becomes DefaultLimit.
+func (r Recurrence) boundedIterator(limit int) (Iterator, int) {
+	if limit == 0 {
+		infinite := false
+		rrules := make([]RRule, len(r.RRules))
+		for i, rrule := range r.RRules {
+			if rrule.IsInfinite() {
+				rrule.Until = DefaultHorizon
+				infinite = true
+			}
+			rrules[i] = rrule
+		}
+		r.RRules = rrules
+
+		if infinite {
+			limit = DefaultLimit
+		}
+	}
+	return r.Iterator(), limit
+}
+
+// sortedTimes returns a sorted copy of tt.
+func sortedTimes(tt []time.Time) []time.Time {
+	sorted := make([]time.Time, len(tt))
+	copy(sorted, tt)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+	return sorted
+}
+
+// Events returns instances of the recurrence up to a limited number, like
+// All, but as periods with the end of each. An instance ends DurationDays
+// later on the calendar and Duration after that, or, if Dtend is set, at the
+// same wall clock time relative to its start as Dtend is to Dtstart, so an
+// event from 9:00 to 17:00 ends at 17:00 even on the day of a daylight saving
+// transition. Instances from RPeriods end with their period. Exclusions match
+// on the start of each period and remove it entirely.
+func (r Recurrence) Events(limit int) []Period {
+	starts := r.All(limit)
+
+	// the wall clock length of the first instance
+	var wall time.Duration
+	if !r.Dtend.IsZero() {
+		wall = floating(r.Dtend.In(r.Dtstart.Location())).Sub(floating(r.Dtstart))
+	}
+
+	periods := make([]Period, len(starts))
+	for i, start := range starts {
+		if p := r.periodOf(start); p >= 0 {
+			periods[i] = r.RPeriods[p]
+			continue
+		}
+
+		end := start.AddDate(0, 0, r.DurationDays).Add(r.Duration)
+		if !r.Dtend.IsZero() {
+			end, _ = unfloat(floating(start).Add(wall), start.Location(), RollForward)
+		}
+		periods[i] = Period{Start: start, End: end}
+	}
+	return periods
+}
+
+// periodOf returns the index of the period in RPeriods that starts at t, or
+// -1 if there is none.
+func (r *Recurrence) periodOf(t time.Time) int {
+	for i, period := range r.RPeriods {
+		if period.Start.Equal(t) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Occurrence is a single instance of a recurrence along with where it came
+// from.
+type Occurrence struct {
+	Time time.Time
+
+	// RRule is the index in RRules of the pattern that generated the
+	// instance, or -1 if it came from RDates. When several sources produce
+	// the same instance, the pattern with the lowest index is reported, and
+	// RDates last.
+	RRule int
+}
+
+// AllDetailed returns instances of the recurrence up to a limited number, like
+// All, but annotated with the source of each instance.
+func (r Recurrence) AllDetailed(limit int) []Occurrence {
+	bounded, limit := r.boundedIterator(limit)
+	it := bounded.(*recurrenceIterator)
+
+	var all []Occurrence
+	for {
+		next := it.Peek()
+		if next == nil {
+			break
+		}
+
+		src := *it.rrules.currentMin
+		if src >= len(r.RRules) {
+			src = -1
+		}
+
+		all = append(all, Occurrence{Time: *next, RRule: src})
+		it.Next()
+
+		if limit > 0 && len(all) == limit {
+			break
+		}
+	}
+	return all
+}
+
+// Iterator returns an iterator for the recurrence. Instances are generated
+// lazily: the iterators of RRules and RDates are merged as they advance, and
+// exclusions are applied on the fly, so infinite recurrences, and infinite
+// exclusion patterns, can be streamed without materializing them. The
+// iterator ends only once every RRule and RDate is exhausted.
 func (r Recurrence) Iterator() Iterator {
+	return r.iteratorFrom(time.Time{})
+}
+
+// iteratorFrom returns an iterator like Iterator, but with each RRULE and
+// EXRULE fast-forwarded to t, so it may skip instances before t without
+// generating them.
+func (r Recurrence) iteratorFrom(t time.Time) Iterator {
 	r.setDtstart()
 
+	rrules := make([]RRule, len(r.RRules))
+	for i, rr := range r.RRules {
+		rrules[i] = rr.fastForward(t)
+	}
+	exrules := make([]RRule, len(r.ExRules))
+	for i, rr := range r.ExRules {
+		exrules[i] = rr.fastForward(t)
+	}
+
 	ri := &recurrenceIterator{
-		rrules:  groupIteratorFromRRules(r.RRules),
-		exrules: groupIteratorFromRRules(r.ExRules),
+		rrules:  groupIteratorFromRRules(rrules),
+		exrules: groupIteratorFromRRules(exrules),
+		exdays:  make(map[date]bool, len(r.ExDays)),
+	}
+
+	for _, exday := range r.ExDays {
+		ri.exdays[dateOf(exday)] = true
 	}
 
-	ri.rrules.iters = append(ri.rrules.iters, &iterator{queue: r.RDates})
-	ri.exrules.iters = append(ri.exrules.iters, &iterator{queue: r.ExDates})
+	ri.rrules.iters = append(ri.rrules.iters, &iterator{queue: sortedTimes(r.RDates)})
+	ri.exrules.iters = append(ri.exrules.iters, &iterator{queue: sortedTimes(r.ExDates)})
 
 	return ri
 }
@@ -115,6 +587,7 @@ func (r Recurrence) Iterator() Iterator {
 type recurrenceIterator struct {
 	rrules  *groupIterator
 	exrules *groupIterator
+	exdays  map[date]bool
 }
 
 func (ri *recurrenceIterator) Peek() *time.Time {
@@ -132,9 +605,9 @@ func (ri *recurrenceIterator) Peek() *time.Time {
 			continue
 		}
 
-		if nextException != nil && nextException.Equal(*next) {
-			next = ri.rrules.Next()
-
+		if (nextException != nil && nextException.Equal(*next)) || ri.exdays[dateOf(*next)] {
+			ri.rrules.Next()
+			next = ri.rrules.Peek()
 			continue
 		}
 
@@ -56,8 +56,38 @@ var recurrenceCases = []struct {
 		},
 		ExDates: []time.Time{time.Date(2018, time.September, 2, 9, 8, 7, 0, time.UTC)},
 	},
-	Dates:  []string{"2018-08-26T09:08:07Z", "2018-08-28T09:08:07Z", "2018-08-31T09:08:07Z", "2018-09-04T09:08:07Z", "2018-09-08T09:08:07Z"},
+	Dates:  []string{"2018-08-26T09:08:07Z", "2018-08-27T09:08:07Z", "2018-08-28T09:08:07Z", "2018-08-31T09:08:07Z", "2018-09-04T09:08:07Z", "2018-09-08T09:08:07Z"},
 	String: "DTSTART:20180825T090807Z\nRRULE:FREQ=DAILY;COUNT=4\nRRULE:FREQ=DAILY;COUNT=8;INTERVAL=2\nEXRULE:FREQ=DAILY;INTERVAL=4\nEXRULE:FREQ=DAILY;INTERVAL=8\nRDATE:20180902T090807Z\nRDATE:20180902T090807Z\nEXDATE:20180902T090807Z\n",
+}, {
+	Name: "Date-valued exclusion",
+	Recurrence: &Recurrence{
+		Dtstart: time.Date(2018, 8, 25, 0, 0, 0, 0, time.UTC),
+		RRules:  []RRule{{Frequency: Daily, Count: 4, ByHours: []int{9, 17}}},
+		ExDates: []time.Time{time.Date(2018, 8, 25, 17, 0, 0, 0, time.UTC)},
+		ExDays:  []time.Time{time.Date(2018, 8, 26, 0, 0, 0, 0, time.UTC)},
+	},
+	Dates:  []string{"2018-08-25T09:00:00Z"},
+	String: "DTSTART:20180825T000000Z\nRRULE:FREQ=DAILY;COUNT=4;BYHOUR=9,17\nEXDATE:20180825T170000Z\nEXDATE;VALUE=DATE:20180826\n",
+}, {
+	Name: "Zoned",
+	Recurrence: &Recurrence{
+		Dtstart: time.Date(2018, 8, 25, 9, 0, 0, 0, NewYork()),
+		RRules:  []RRule{{Frequency: Daily, Until: time.Date(2018, 8, 28, 9, 0, 0, 0, NewYork())}},
+		RDates:  []time.Time{time.Date(2018, 8, 30, 12, 0, 0, 0, NewYork())},
+		ExDates: []time.Time{time.Date(2018, 8, 26, 9, 0, 0, 0, NewYork())},
+	},
+	Dates:  []string{"2018-08-25T09:00:00-04:00", "2018-08-27T09:00:00-04:00", "2018-08-28T09:00:00-04:00", "2018-08-30T12:00:00-04:00"},
+	String: "DTSTART;TZID=America/New_York:20180825T090000\nRRULE:FREQ=DAILY;UNTIL=20180828T130000Z\nRDATE;TZID=America/New_York:20180830T120000\nEXDATE;TZID=America/New_York:20180826T090000\n",
+}, {
+	Name: "Floating",
+	Recurrence: &Recurrence{
+		Dtstart:          time.Date(2018, 8, 25, 9, 0, 0, 0, time.UTC),
+		FloatingLocation: true,
+		RRules:           []RRule{{Frequency: Weekly, Count: 2}},
+		RDates:           []time.Time{time.Date(2018, 8, 26, 9, 0, 0, 0, time.UTC)},
+	},
+	Dates:  []string{"2018-08-25T09:00:00Z", "2018-08-26T09:00:00Z", "2018-09-01T09:00:00Z"},
+	String: "DTSTART:20180825T090000\nRRULE:FREQ=WEEKLY;COUNT=2\nRDATE:20180826T090000\n",
 }}
 
 func TestRecurrence(t *testing.T) {
@@ -75,9 +105,385 @@ func TestRecurrence(t *testing.T) {
 
 			t.Log(src)
 			assert.Equal(t, tc.String, src)
+			assert.Equal(t, rfcAll(tc.Recurrence.ExDays), rfcAll(parsed.ExDays))
+			assert.Equal(t, rfcAll(tc.Recurrence.RDates), rfcAll(parsed.RDates))
+			assert.Equal(t, rfcAll(tc.Recurrence.ExDates), rfcAll(parsed.ExDates))
+			assert.Equal(t, tc.Recurrence.FloatingLocation, parsed.FloatingLocation)
+			assert.Equal(t, src, parsed.String(), "String must be stable through a round trip")
 
 			dates := All(tc.Recurrence.Iterator(), 0)
 			assert.Equal(t, tc.Dates, rfcAll(dates))
 		})
 	}
 }
+
+func TestRecurrenceLocation(t *testing.T) {
+	zoned, err := ParseRecurrence([]byte("DTSTART;TZID=America/New_York:20180825T090807\nRRULE:FREQ=DAILY;COUNT=1"), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, NewYork().String(), zoned.Location().String())
+	assert.False(t, zoned.FloatingLocation)
+	assert.Equal(t, NewYork().String(), zoned.RRules[0].Location().String())
+
+	floating, err := ParseRecurrence([]byte("DTSTART:20180825T090807\nRRULE:FREQ=DAILY;COUNT=1"), Phoenix())
+	require.NoError(t, err)
+	assert.Equal(t, Phoenix().String(), floating.Location().String())
+	assert.True(t, floating.FloatingLocation)
+
+	assert.Equal(t, time.Local, RRule{Frequency: Daily}.Location())
+}
+
+func TestRecurrenceCountdown(t *testing.T) {
+	floating, err := ParseRecurrence([]byte("DTSTART:19991231T000000\nRRULE:FREQ=YEARLY;COUNT=2;BYHOUR=-1;BYMINUTE=-1;BYSECOND=-10"), Phoenix())
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(1999, 12, 31, 23, 59, 50, 0, Phoenix()),
+		time.Date(2000, 12, 31, 23, 59, 50, 0, Phoenix()),
+	}, All(floating.Iterator(), 0))
+
+	zoned, err := ParseRecurrence([]byte("DTSTART;TZID=America/New_York:19991231T000000\nRRULE:FREQ=YEARLY;COUNT=2;BYHOUR=-1;BYMINUTE=-1;BYSECOND=-10"), Phoenix())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1999-12-31T23:59:50-05:00", "2000-12-31T23:59:50-05:00"}, rfcAll(All(zoned.Iterator(), 0)))
+
+	// as limits, negative values match from the end of the minute and hour
+	minutely := RRule{
+		Frequency: Minutely,
+		Count:     3,
+		Dtstart:   time.Date(2018, 8, 25, 9, 0, 0, 0, time.UTC),
+		ByMinutes: []int{-1, 0},
+		ByHours:   []int{-15},
+	}
+	assert.Equal(t, []string{"2018-08-25T09:00:00Z", "2018-08-25T09:59:00Z", "2018-08-26T09:00:00Z"}, rfcAll(All(minutely.Iterator(), 0)))
+}
+
+func TestRecurrenceDateValued(t *testing.T) {
+	r, err := ParseRecurrence([]byte("DTSTART;VALUE=DATE:20180825\nRRULE:FREQ=WEEKLY;COUNT=3;BYDAY=SA,MO"), NewYork())
+	require.NoError(t, err)
+	assert.True(t, r.DateValued)
+	assert.Equal(t, []string{"2018-08-25T00:00:00-04:00", "2018-08-27T00:00:00-04:00", "2018-09-01T00:00:00-04:00"}, rfcAll(All(r.Iterator(), 0)))
+
+	for _, src := range []string{
+		"DTSTART;VALUE=DATE:20180825\nRRULE:FREQ=DAILY;BYHOUR=9",
+		"DTSTART;VALUE=DATE:20180825\nRRULE:FREQ=DAILY;BYMINUTE=30",
+		"DTSTART;VALUE=DATE:20180825\nEXRULE:FREQ=WEEKLY;BYSECOND=5",
+		"DTSTART;VALUE=DATE:20180825\nRRULE:FREQ=HOURLY",
+	} {
+		_, err := ParseRecurrence([]byte(src), time.UTC)
+		assert.Error(t, err, src)
+	}
+
+	dateTime := &Recurrence{Dtstart: time.Date(2018, 8, 25, 9, 0, 0, 0, time.UTC), RRules: []RRule{{Frequency: Daily, ByHours: []int{9}}}}
+	assert.NoError(t, dateTime.Validate())
+	dateTime.DateValued = true
+	assert.Error(t, dateTime.Validate())
+}
+
+func TestRecurrenceExDateInstant(t *testing.T) {
+	r := &Recurrence{
+		Dtstart: time.Date(2018, 8, 25, 0, 0, 0, 0, time.UTC),
+		RRules:  []RRule{{Frequency: Daily, Count: 6, ByHours: []int{9, 17}}},
+		ExDates: []time.Time{time.Date(2018, 8, 26, 9, 0, 0, 0, time.UTC)},
+	}
+	assert.Equal(t, []string{"2018-08-25T09:00:00Z", "2018-08-25T17:00:00Z", "2018-08-26T17:00:00Z", "2018-08-27T09:00:00Z", "2018-08-27T17:00:00Z"}, rfcAll(All(r.Iterator(), 0)))
+
+	r = &Recurrence{
+		Dtstart: time.Date(2018, 8, 25, 0, 0, 0, 0, time.UTC),
+		RRules:  []RRule{{Frequency: Daily, Count: 6, ByHours: []int{9, 17}}},
+		ExDays:  []time.Time{time.Date(2018, 8, 26, 0, 0, 0, 0, time.UTC)},
+	}
+	assert.Equal(t, []string{"2018-08-25T09:00:00Z", "2018-08-25T17:00:00Z", "2018-08-27T09:00:00Z", "2018-08-27T17:00:00Z"}, rfcAll(All(r.Iterator(), 0)))
+}
+
+func TestRecurrenceExRule(t *testing.T) {
+	start := time.Date(2018, 8, 25, 9, 8, 7, 0, time.UTC)
+	r := Recurrence{
+		Dtstart: start,
+		RRules:  []RRule{{Frequency: Weekly, Count: 8, ByWeekdays: []QualifiedWeekday{{WD: time.Tuesday}}}},
+		ExRules: []RRule{{Frequency: Weekly, Interval: 2, ByWeekdays: []QualifiedWeekday{{WD: time.Tuesday}}}},
+	}
+
+	// every other Tuesday remains
+	assert.Equal(t, []string{"2018-08-28T09:08:07Z", "2018-09-11T09:08:07Z", "2018-09-25T09:08:07Z", "2018-10-09T09:08:07Z"}, rfcAll(r.All(0)))
+
+	// an instance only shortly after an excluded one is kept
+	later := time.Date(2018, 9, 4, 9, 8, 7, 500000000, time.UTC)
+	r.RDates = []time.Time{later}
+	all := r.All(0)
+	require.Len(t, all, 5)
+	assert.Equal(t, later, all[1])
+}
+
+func TestRecurrenceMultipleRRules(t *testing.T) {
+	r, err := ParseRecurrence([]byte("DTSTART:20180825T090000Z\nRRULE:FREQ=DAILY;COUNT=4\nRRULE:FREQ=WEEKLY;COUNT=3;BYDAY=TU;BYHOUR=17\nRDATE:20180826T090000Z,20180830T120000Z"), time.UTC)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"2018-08-25T09:00:00Z",
+		"2018-08-26T09:00:00Z",
+		"2018-08-27T09:00:00Z",
+		"2018-08-28T09:00:00Z",
+		"2018-08-28T17:00:00Z",
+		"2018-08-30T12:00:00Z",
+		"2018-09-04T17:00:00Z",
+		"2018-09-11T17:00:00Z",
+	}, rfcAll(r.All(0)))
+}
+
+func TestRecurrenceEventsExcluded(t *testing.T) {
+	r := Recurrence{
+		Dtstart:  time.Date(2018, 8, 25, 9, 0, 0, 0, time.UTC),
+		RRules:   []RRule{{Frequency: Daily, Count: 3}},
+		ExDates:  []time.Time{time.Date(2018, 8, 26, 9, 0, 0, 0, time.UTC)},
+		Duration: 90 * time.Minute,
+	}
+
+	assert.Equal(t, []Period{
+		{Start: time.Date(2018, 8, 25, 9, 0, 0, 0, time.UTC), End: time.Date(2018, 8, 25, 10, 30, 0, 0, time.UTC)},
+		{Start: time.Date(2018, 8, 27, 9, 0, 0, 0, time.UTC), End: time.Date(2018, 8, 27, 10, 30, 0, 0, time.UTC)},
+	}, r.Events(0))
+}
+
+func TestRecurrenceEvents(t *testing.T) {
+	// New York sets clocks forward on 2018-03-11, so a DURATION of 8 hours
+	// ends an hour later on the wall clock that day, while DTEND keeps 17:00
+	src := "DTSTART;TZID=America/New_York:20180310T090000\nRRULE:FREQ=DAILY;COUNT=3\n"
+
+	fixed, err := ParseRecurrence([]byte(src+"DURATION:PT8H\n"), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, 8*time.Hour, fixed.Duration)
+
+	var ends []string
+	for _, event := range fixed.Events(0) {
+		ends = append(ends, event.End.Format(time.RFC3339))
+	}
+	assert.Equal(t, []string{"2018-03-10T17:00:00-05:00", "2018-03-11T17:00:00-04:00", "2018-03-12T17:00:00-04:00"}, ends)
+
+	fixed.Dtstart = time.Date(2018, 3, 10, 22, 0, 0, 0, NewYork())
+	fixed.setDtstart()
+	assert.Equal(t, "2018-03-11T07:00:00-04:00", fixed.Events(1)[0].End.Format(time.RFC3339))
+
+	wall, err := ParseRecurrence([]byte(src+"DTEND;TZID=America/New_York:20180311T070000\n"), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, 21*time.Hour, wall.Duration)
+
+	ends = nil
+	for _, event := range wall.Events(0) {
+		ends = append(ends, event.End.Format(time.RFC3339))
+	}
+	assert.Equal(t, []string{"2018-03-11T07:00:00-04:00", "2018-03-12T07:00:00-04:00", "2018-03-13T07:00:00-04:00"}, ends)
+	assert.Equal(t, "DTSTART;TZID=America/New_York:20180310T090000\nDTEND;TZID=America/New_York:20180311T070000\nRRULE:FREQ=DAILY;COUNT=3\n", wall.String())
+
+	// a DTEND in another zone is read on the wall clock of DTSTART
+	utcEnd, err := ParseRecurrence([]byte(src+"DTEND:20180310T170000Z\n"), time.UTC)
+	require.NoError(t, err)
+	ends = nil
+	for _, event := range utcEnd.Events(0) {
+		ends = append(ends, event.End.Format(time.RFC3339))
+	}
+	assert.Equal(t, []string{"2018-03-10T12:00:00-05:00", "2018-03-11T12:00:00-04:00", "2018-03-12T12:00:00-04:00"}, ends)
+
+	// days are nominal, so a day-long event ends at the same wall clock time
+	// the next day across the transition, for all-day and timed events alike
+	allDay, err := ParseRecurrence([]byte("DTSTART;VALUE=DATE:20180310\nDURATION:P1D\nRRULE:FREQ=DAILY;COUNT=2\n"), NewYork())
+	require.NoError(t, err)
+	assert.Equal(t, "DTSTART;VALUE=DATE:20180310\nDURATION:P1D\nRRULE:FREQ=DAILY;COUNT=2\n", allDay.String())
+	ends = nil
+	for _, event := range allDay.Events(0) {
+		ends = append(ends, event.End.Format(time.RFC3339))
+	}
+	assert.Equal(t, []string{"2018-03-11T00:00:00-05:00", "2018-03-12T00:00:00-04:00"}, ends)
+
+	timed, err := ParseRecurrence([]byte(src+"DURATION:P1DT1H\n"), time.UTC)
+	require.NoError(t, err)
+	ends = nil
+	for _, event := range timed.Events(0) {
+		ends = append(ends, event.End.Format(time.RFC3339))
+	}
+	assert.Equal(t, []string{"2018-03-11T10:00:00-04:00", "2018-03-12T10:00:00-04:00", "2018-03-13T10:00:00-04:00"}, ends)
+
+	// without a limit, infinite patterns stop at DefaultHorizon
+	yearly := Recurrence{Dtstart: time.Date(2098, 7, 4, 9, 0, 0, 0, time.UTC), RRules: []RRule{{Frequency: Yearly}}, Duration: time.Hour}
+	assert.Len(t, yearly.Events(0), 2)
+
+	for _, bad := range []string{"DURATION:PT1H\nDTEND:20180311T070000Z", "DTEND;TZID=America/New_York:20180310T080000", "DURATION:-PT1H", "DURATION:1H"} {
+		_, err := ParseRecurrence([]byte(src+bad), time.UTC)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRecurrenceClone(t *testing.T) {
+	r, err := ParseRecurrence([]byte("DTSTART:20180825T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=SA\nEXRULE:FREQ=MONTHLY;BYMONTHDAY=1\nRDATE:20180901T120000Z\nEXDATE:20180908T090000Z"), nil)
+	require.NoError(t, err)
+	src := r.String()
+
+	clone := r.Clone()
+	assert.Equal(t, r, clone)
+
+	clone.RRules[0].ByWeekdays[0].WD = time.Sunday
+	clone.ExRules[0].ByMonthDays[0] = 2
+	clone.RDates[0] = clone.RDates[0].Add(time.Hour)
+	clone.ExDates = append(clone.ExDates[:0], time.Time{})
+	clone.Raw[0] = ""
+
+	assert.Equal(t, src, r.String())
+	assert.Equal(t, "DTSTART:20180825T090000Z", r.Raw[0])
+}
+
+func TestRecurrenceShift(t *testing.T) {
+	r := &Recurrence{
+		Dtstart: time.Date(2018, 8, 25, 9, 0, 0, 0, time.UTC),
+		RRules:  []RRule{{Frequency: Daily, Until: time.Date(2018, 8, 28, 9, 0, 0, 0, time.UTC)}},
+		RDates:  []time.Time{time.Date(2018, 9, 1, 12, 0, 0, 0, time.UTC)},
+		ExDates: []time.Time{time.Date(2018, 8, 26, 9, 0, 0, 0, time.UTC)},
+	}
+
+	shifted, err := r.Shift(30 * time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2018-08-25T09:30:00Z", "2018-08-27T09:30:00Z", "2018-08-28T09:30:00Z", "2018-09-01T12:30:00Z"}, rfcAll(All(shifted.Iterator(), 0)))
+
+	// the original is untouched
+	assert.Equal(t, []string{"2018-08-25T09:00:00Z", "2018-08-27T09:00:00Z", "2018-08-28T09:00:00Z", "2018-09-01T12:00:00Z"}, rfcAll(All(r.Iterator(), 0)))
+
+	r.RRules[0].ByHours = []int{9}
+	_, err = r.Shift(30 * time.Minute)
+	assert.Error(t, err)
+
+	// a date UNTIL moves to the day of the shifted last instance
+	dated, err := ParseRecurrence([]byte("DTSTART:20180825T210000Z\nRRULE:FREQ=DAILY;UNTIL=20180827"), time.UTC)
+	require.NoError(t, err)
+
+	shifted, err = dated.Shift(6 * time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2018-08-26T03:00:00Z", "2018-08-27T03:00:00Z", "2018-08-28T03:00:00Z"}, rfcAll(shifted.All(0)))
+
+	shifted, err = dated.Shift(-22 * time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2018-08-24T23:00:00Z", "2018-08-25T23:00:00Z", "2018-08-26T23:00:00Z"}, rfcAll(shifted.All(0)))
+
+	hourly, err := ParseRecurrence([]byte("DTSTART:20180825T210000Z\nRRULE:FREQ=HOURLY;UNTIL=20180827"), time.UTC)
+	require.NoError(t, err)
+	_, err = hourly.Shift(6 * time.Hour)
+	assert.Error(t, err)
+	_, err = hourly.Shift(24 * time.Hour)
+	assert.NoError(t, err)
+}
+
+func TestRecurrenceAllDetailed(t *testing.T) {
+	r := Recurrence{
+		Dtstart: time.Date(2018, 8, 25, 9, 0, 0, 0, time.UTC), // a saturday
+		RRules: []RRule{
+			{Frequency: Daily, Count: 3},
+			{Frequency: Weekly, Count: 2, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}}},
+		},
+		RDates: []time.Time{time.Date(2018, 8, 30, 12, 0, 0, 0, time.UTC)},
+	}
+
+	assert.Equal(t, []Occurrence{
+		{Time: time.Date(2018, 8, 25, 9, 0, 0, 0, time.UTC), RRule: 0},
+		{Time: time.Date(2018, 8, 26, 9, 0, 0, 0, time.UTC), RRule: 0},
+		{Time: time.Date(2018, 8, 27, 9, 0, 0, 0, time.UTC), RRule: 0},
+		{Time: time.Date(2018, 8, 30, 12, 0, 0, 0, time.UTC), RRule: -1},
+		{Time: time.Date(2018, 9, 3, 9, 0, 0, 0, time.UTC), RRule: 1},
+	}, r.AllDetailed(0))
+
+	// without a limit, infinite patterns stop at DefaultHorizon
+	r = Recurrence{
+		Dtstart: time.Date(2098, 7, 4, 9, 0, 0, 0, time.UTC),
+		RRules:  []RRule{{Frequency: Yearly}},
+	}
+	assert.Equal(t, []Occurrence{
+		{Time: time.Date(2098, 7, 4, 9, 0, 0, 0, time.UTC), RRule: 0},
+		{Time: time.Date(2099, 7, 4, 9, 0, 0, 0, time.UTC), RRule: 0},
+	}, r.AllDetailed(0))
+}
+
+func TestRecurrenceIteratorStreaming(t *testing.T) {
+	start := time.Date(2018, 8, 24, 9, 0, 0, 0, time.UTC) // a friday
+
+	// every day but weekends, forever, with an exclusion that never ends
+	r := Recurrence{
+		Dtstart: start,
+		RRules:  []RRule{{Frequency: Daily}},
+		ExRules: []RRule{{Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{WD: time.Saturday}, {WD: time.Sunday}}}},
+		ExDates: []time.Time{time.Date(2018, 8, 29, 9, 0, 0, 0, time.UTC)},
+		RDates:  []time.Time{time.Date(2018, 8, 25, 12, 0, 0, 0, time.UTC)},
+	}
+
+	it := r.Iterator()
+	assert.Equal(t, "2018-08-24T09:00:00Z", it.Peek().Format(time.RFC3339))
+
+	var streamed []time.Time
+	for len(streamed) < 6 {
+		streamed = append(streamed, *it.Next())
+	}
+	assert.Equal(t, []string{
+		"2018-08-24T09:00:00Z", "2018-08-25T12:00:00Z", "2018-08-27T09:00:00Z",
+		"2018-08-28T09:00:00Z", "2018-08-30T09:00:00Z", "2018-08-31T09:00:00Z",
+	}, rfcAll(streamed))
+
+	// the iterator ends only when the inclusions do, even if exclusions remain
+	r.RRules[0].Count = 4
+	assert.Equal(t, []string{"2018-08-24T09:00:00Z", "2018-08-25T12:00:00Z", "2018-08-27T09:00:00Z"}, rfcAll(All(r.Iterator(), 0)))
+}
+
+func TestRecurrenceAll(t *testing.T) {
+	start := time.Date(2099, 12, 28, 9, 0, 0, 0, time.UTC)
+
+	r := Recurrence{
+		Dtstart: start,
+		RRules: []RRule{
+			{Frequency: Daily, Interval: 2},
+			{Frequency: Daily, Interval: 3},
+		},
+		// unsorted, and repeating an instance of the rules
+		RDates:  []time.Time{start.Add(36 * time.Hour), start.Add(12 * time.Hour), start.AddDate(0, 0, 2)},
+		ExRules: []RRule{{Frequency: Weekly, Count: 1}},
+		ExDates: []time.Time{start.AddDate(0, 0, 3), start.AddDate(0, 0, 4).Add(time.Second)},
+	}
+
+	// the infinite rules end at DefaultHorizon
+	assert.Equal(t, []string{
+		"2099-12-28T21:00:00Z", "2099-12-29T21:00:00Z", "2099-12-30T09:00:00Z",
+	}, rfcAll(r.All(0)))
+
+	assert.Equal(t, []string{"2099-12-28T21:00:00Z", "2099-12-29T21:00:00Z"}, rfcAll(r.All(2)))
+
+	// with a limit, the rules continue past DefaultHorizon
+	assert.Equal(t, "2100-01-01T09:00:00Z", r.All(4)[3].Format(time.RFC3339))
+}
+
+func TestRecurrenceAllLimit(t *testing.T) {
+	r, err := ParseRecurrence([]byte("DTSTART:20180825T122700Z\nRRULE:FREQ=MINUTELY\nRRULE:FREQ=DAILY;BYHOUR=12;BYMINUTE=30;BYSECOND=30\nEXRULE:FREQ=MINUTELY;INTERVAL=2"), time.UTC)
+	require.NoError(t, err)
+
+	// the limit counts instances left after every other minute is excluded
+	assert.Equal(t, []string{
+		"2018-08-25T12:28:00Z",
+		"2018-08-25T12:30:00Z",
+		"2018-08-25T12:30:30Z",
+		"2018-08-25T12:32:00Z",
+		"2018-08-25T12:34:00Z",
+	}, rfcAll(r.All(5)))
+}
+
+func TestInLocation(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	// floating 9am in New York, which springs forward on March 11th, two
+	// weeks before London does
+	r, err := ParseRecurrence([]byte("DTSTART:20180309T090000\nRRULE:FREQ=WEEKLY;COUNT=3"), NewYork())
+	require.NoError(t, err)
+
+	all := All(InLocation(r.Iterator(), london), 0)
+	assert.Equal(t, []string{"2018-03-09T14:00:00Z", "2018-03-16T13:00:00Z", "2018-03-23T13:00:00Z"}, rfcAll(all))
+	for _, tt := range all {
+		assert.Equal(t, london, tt.Location())
+	}
+
+	r.Dtstart = r.Dtstart.AddDate(0, 0, 7)
+	r.setDtstart()
+	it := InLocation(r.Iterator(), london)
+	assert.Equal(t, "2018-03-16T13:00:00Z", it.Peek().Format(time.RFC3339))
+	assert.Equal(t, "2018-03-16T13:00:00Z", it.Next().Format(time.RFC3339))
+	assert.Equal(t, "2018-03-30T14:00:00+01:00", All(it, 0)[1].Format(time.RFC3339))
+}
@@ -6,6 +6,7 @@
 package rrule
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sort"
@@ -19,12 +20,15 @@ type RRule struct {
 	// Either Until or Count may be set, but not both
 	Until         time.Time
 	UntilFloating bool // If true, the RRule will encode using local time (no offset).
+	UntilDate     bool // If true, Until is a date (UNTIL=20180830), so every instance on that day is included. Dates are floating.
 
 	Count uint64
 
 	// Dtstart is not actually part of the RRule when
 	// encoded, but it's included here as a field because
-	// it's required when expading the pattern.
+	// it's required when expading the pattern. It's only
+	// an instance of the pattern if it matches the BYxxx
+	// parts; see First.
 	//
 	// If zero, time.Now is used when an iterator is generated.
 	Dtstart time.Time
@@ -32,23 +36,68 @@ type RRule struct {
 	// 0 means the default value, which is 1.
 	Interval int
 
-	BySeconds     []int // 0 to 59
-	ByMinutes     []int // 0 to 59
-	ByHours       []int // 0 to 23
+	// Negative values of BYxxx parts count back from the end of the minute,
+	// hour, day, and so on, so -1 is the last.
+	BySeconds     []int // 0 to 60, or -60 to -1
+	ByMinutes     []int // 0 to 59, or -60 to -1
+	ByHours       []int // 0 to 23, or -24 to -1
 	ByWeekdays    []QualifiedWeekday
-	ByMonthDays   []int // 1 to 31
-	ByWeekNumbers []int // 1 to 53
+	ByMonthDays   []int // 1 to 31, or -31 to -1
+	ByWeekNumbers []int // 1 to 53, or -53 to -1
 	ByMonths      []time.Month
-	ByYearDays    []int // 1 to 366
-	BySetPos      []int // -366 to 366
-
-	ib invalidBehavior
+	ByYearDays    []int // 1 to 366, or -366 to -1
+	BySetPos      []int // 1 to 366, or -366 to -1
+
+	// InvalidBehavior decides what happens to instances of MONTHLY and
+	// YEARLY patterns on days that don't exist, such as BYMONTHDAY=31 in
+	// April. The default, OmitInvalid, skips them, as RFC 5545 specifies.
+	InvalidBehavior InvalidBehavior
+
+	// GapBehavior decides what happens to instances of DAILY and less
+	// frequent patterns whose local time is skipped by a daylight saving
+	// transition. The default, RollForward, moves them forward by the length
+	// of the gap, as RFC 5545 specifies.
+	GapBehavior GapBehavior
+
+	WeekStart *time.Weekday // if nil, DefaultWeekStart. Groups days into weeks for WEEKLY intervals and BYWEEKNO
+
+	// Raw is the text the pattern was parsed from, exactly as given. It's
+	// empty for patterns constructed in code, and isn't updated when other
+	// fields are changed. See RawString.
+	Raw string
+}
 
-	WeekStart *time.Weekday // if nil, Monday
+// RawString returns Raw if the pattern was parsed, or String otherwise. This
+// reproduces parsed input byte-for-byte, even where String would format it
+// differently.
+func (rrule RRule) RawString() string {
+	if rrule.Raw != "" {
+		return rrule.Raw
+	}
+	return rrule.String()
 }
 
-// Validate checks that the pattern is valid.
+// Validate checks that the pattern is valid. Besides the constraints of RFC
+// 5545, an Until before Dtstart is reported, since the pattern then has no
+// instances, which is almost always a mistake.
 func (rrule RRule) Validate() error {
+	if err := rrule.validateParts(); err != nil {
+		return err
+	}
+
+	if !rrule.Until.IsZero() && !rrule.Dtstart.IsZero() {
+		until := rrule.lastUntil()
+		if until.Before(rrule.Dtstart) {
+			return fmt.Errorf("UNTIL %s is before DTSTART %s", until.Format(time.RFC3339), rrule.Dtstart.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+// validateParts checks the rule parts of the pattern against the constraints
+// of RFC 5545.
+func (rrule RRule) validateParts() error {
 	if rrule.Frequency != Yearly && rrule.Frequency != Monthly {
 		for _, wd := range rrule.ByWeekdays {
 			if wd.N != 0 {
@@ -91,34 +140,208 @@ func (rrule RRule) Validate() error {
 		}
 	}
 
+	// negative values count back from the end of the minute, hour, day, and
+	// so on, and 60 is a leap second.
+	ranges := []struct {
+		part     string
+		values   []int
+		min, max int
+		zero     bool
+	}{
+		{"BYSECOND", rrule.BySeconds, -60, 60, true},
+		{"BYMINUTE", rrule.ByMinutes, -60, 59, true},
+		{"BYHOUR", rrule.ByHours, -24, 23, true},
+		{"BYMONTHDAY", rrule.ByMonthDays, -31, 31, false},
+		{"BYYEARDAY", rrule.ByYearDays, -366, 366, false},
+		{"BYWEEKNO", rrule.ByWeekNumbers, -53, 53, false},
+	}
+	for _, r := range ranges {
+		for _, v := range r.values {
+			if v < r.min || v > r.max || (v == 0 && !r.zero) {
+				if r.zero {
+					return fmt.Errorf("%s value %d must be between [%d,%d]", r.part, v, r.min, r.max)
+				}
+				return fmt.Errorf("%s value %d must be between [%d,-1] or [1,%d]", r.part, v, r.min, r.max)
+			}
+		}
+	}
+
+	for _, m := range rrule.ByMonths {
+		if m < time.January || m > time.December {
+			return fmt.Errorf("BYMONTH value %d must be between [1,12]", m)
+		}
+	}
+
+	for _, wd := range rrule.ByWeekdays {
+		if wd.WD < time.Sunday || wd.WD > time.Saturday {
+			return fmt.Errorf("BYDAY weekday %d is not valid", wd.WD)
+		}
+		if wd.N < -53 || wd.N > 53 {
+			return fmt.Errorf("BYDAY value %s must have a numeric component between [-53,-1] or [1,53]", qualifiedWeekdayString(wd))
+		}
+	}
+
 	return nil
 }
 
-// Iterator returns an Iterator for the pattern. The pattern must be valid or Iterator will panic.
+// Iterator returns an Iterator for the pattern. The pattern must be valid or
+// Iterator will panic, except that an Until before Dtstart simply produces no
+// instances.
 func (rrule RRule) Iterator() Iterator {
-	err := rrule.Validate()
+	err := rrule.validateParts()
 	if err != nil {
 		panic(err)
 	}
 
+	// resolve a floating or date UNTIL to the latest instant it admits
+	rrule.Until = rrule.lastUntil()
+
+	// patterns of DAILY and up repeat on the wall clock, so they're expanded
+	// in floating time and each instance is moved back into the location of
+	// Dtstart, rather than relying on time.Date to resolve wall clock times
+	// skipped by daylight saving transitions.
+	var loc *time.Location
+	start := rrule.Dtstart
+	if rrule.Frequency >= Daily {
+		if start.IsZero() {
+			start = time.Now()
+		}
+		if start.Location() != time.UTC {
+			loc = start.Location()
+			rrule.Dtstart = floating(start)
+		}
+	}
+
+	var it *iterator
 	switch rrule.Frequency {
 	case Secondly:
-		return setSecondly(rrule)
+		it = setSecondly(rrule)
 	case Minutely:
-		return setMinutely(rrule)
+		it = setMinutely(rrule)
 	case Hourly:
-		return setHourly(rrule)
+		it = setHourly(rrule)
 	case Daily:
-		return setDaily(rrule)
+		it = setDaily(rrule)
 	case Weekly:
-		return setWeekly(rrule)
+		it = setWeekly(rrule)
 	case Monthly:
-		return setMonthly(rrule)
+		it = setMonthly(rrule)
 	case Yearly:
-		return setYearly(rrule)
+		it = setYearly(rrule)
 	default:
 		panic(fmt.Sprintf("invalid frequency %v", rrule.Frequency))
 	}
+
+	if loc != nil {
+		it.minTime = start
+		it.loc = loc
+		it.gap = rrule.GapBehavior
+	}
+
+	if rrule.IsSimple() {
+		// every key time of a simple pattern is an instance, so validation
+		// and expansion can be skipped entirely.
+		it.valid = nil
+		it.variations = nil
+	}
+
+	return it
+}
+
+// DefaultHorizon is the latest instance RRule.All returns for a pattern with
+// neither COUNT nor UNTIL when no limit is given. It's a safety net against
+// generating instances practically forever, and may be changed to suit an
+// application.
+var DefaultHorizon = time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// DefaultLimit is the most instances RRule.All returns for a pattern with
+// neither COUNT nor UNTIL when no limit is given. Like DefaultHorizon, it's a
+// safety net, here for frequent patterns, such as SECONDLY, with too many
+// instances before the horizon to hold in memory. It may be changed to suit
+// an application, and is ignored if 0.
+var DefaultLimit = 100000
+
+// All returns the instances of the pattern up to a limited number. If limit
+// is 0 and the pattern is infinite, instances are returned up to and
+// including DefaultHorizon, and no more than DefaultLimit are returned. To
+// use a different horizon for a single pattern, set Until on a copy of it
+// instead.
+func (rrule RRule) All(limit int) []time.Time {
+	it, limit := rrule.boundedIterator(limit)
+	return All(it, limit)
+}
+
+// boundedIterator returns an Iterator for the pattern along with the limit to
+// apply to it. If limit is 0 and the pattern is infinite, the iterator ends at
+// DefaultHorizon and the limit becomes DefaultLimit. Every All-style method
+// starts from it, so none of them run forever on an infinite pattern.
+func (rrule RRule) boundedIterator(limit int) (Iterator, int) {
+	if limit == 0 && rrule.IsInfinite() {
+		rrule.Until = DefaultHorizon
+		limit = DefaultLimit
+	}
+	return rrule.Iterator(), limit
+}
+
+// IteratorContext returns an Iterator for the pattern, like Iterator, that
+// ends early once ctx is done. The context is checked periodically while
+// searching for instances, so a pattern that matches rarely, or never, can
+// still be bounded by a deadline. Check ctx.Err after the iterator ends to
+// tell cancellation from the end of the pattern.
+func (rrule RRule) IteratorContext(ctx context.Context) Iterator {
+	it := rrule.Iterator().(*iterator)
+	it.ctx = ctx
+	return it
+}
+
+// AllContext returns the instances of the pattern up to a limited number,
+// like All, unless ctx is done first. Then it returns the instances found so
+// far along with ctx.Err().
+func (rrule RRule) AllContext(ctx context.Context, limit int) ([]time.Time, error) {
+	bounded, limit := rrule.boundedIterator(limit)
+	it := bounded.(*iterator)
+	it.ctx = ctx
+	all := All(it, limit)
+	return all, it.err
+}
+
+// IsSimple reports whether the pattern has no BYxxx rule parts, meaning it's
+// fully described by its frequency, interval, and COUNT or UNTIL.
+func (rrule RRule) IsSimple() bool {
+	return len(rrule.BySeconds) == 0 &&
+		len(rrule.ByMinutes) == 0 &&
+		len(rrule.ByHours) == 0 &&
+		len(rrule.ByWeekdays) == 0 &&
+		len(rrule.ByMonthDays) == 0 &&
+		len(rrule.ByWeekNumbers) == 0 &&
+		len(rrule.ByMonths) == 0 &&
+		len(rrule.ByYearDays) == 0 &&
+		len(rrule.BySetPos) == 0
+}
+
+// lastUntil returns the latest instance Until admits. A floating Until is a
+// wall clock time in the location of Dtstart, and a date admits every
+// instance on that day, since RFC 5545 compares a DATE UNTIL with the date of
+// each instance.
+func (rrule RRule) lastUntil() time.Time {
+	until := rrule.Until
+	if until.IsZero() {
+		return until
+	}
+	if rrule.UntilFloating && !rrule.Dtstart.IsZero() {
+		until = time.Date(until.Year(), until.Month(), until.Day(), until.Hour(), until.Minute(), until.Second(), until.Nanosecond(), rrule.Dtstart.Location())
+	}
+	if rrule.UntilDate {
+		until = until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return until
+}
+
+// IsInfinite reports whether the pattern has neither COUNT nor UNTIL, so that
+// its instances never end. All bounds an infinite pattern by DefaultHorizon
+// and DefaultLimit when no limit is given; use Iterator to stream it instead.
+func (rrule RRule) IsInfinite() bool {
+	return rrule.Count == 0 && rrule.Until.IsZero()
 }
 
 func setSecondly(rrule RRule) *iterator {
@@ -219,7 +442,7 @@ func setSecondly(rrule RRule) *iterator {
 			validWeekday(rrule.ByWeekdays),
 			validMonthDay(rrule.ByMonthDays),
 			validMonth(rrule.ByMonths),
-			validWeek(rrule.ByWeekNumbers),
+			validWeek(rrule.ByWeekNumbers, rrule.weekStart()),
 			validYearDay(rrule.ByYearDays),
 		),
 
@@ -258,7 +481,7 @@ func setMinutely(rrule RRule) *iterator {
 
 		valid: combineLimiters(
 			validMonth(rrule.ByMonths),
-			validWeek(rrule.ByWeekNumbers),
+			validWeek(rrule.ByWeekNumbers, rrule.weekStart()),
 			validYearDay(rrule.ByYearDays),
 			validMonthDay(rrule.ByMonthDays),
 			validWeekday(rrule.ByWeekdays),
@@ -303,7 +526,7 @@ func setHourly(rrule RRule) *iterator {
 
 		valid: combineLimiters(
 			validMonth(rrule.ByMonths),
-			validWeek(rrule.ByWeekNumbers),
+			validWeek(rrule.ByWeekNumbers, rrule.weekStart()),
 			validYearDay(rrule.ByYearDays),
 			validMonthDay(rrule.ByMonthDays),
 			validWeekday(rrule.ByWeekdays),
@@ -328,39 +551,39 @@ func setMonthly(rrule RRule) *iterator {
 		start = time.Now()
 	}
 
-	current := start
-
 	interval := 1
 	if rrule.Interval != 0 {
 		interval = rrule.Interval
 	}
 
+	// the day of Dtstart only matters when no BYxxx part sets the day. Either
+	// way, the key time of each month must stay in that month.
+	ib := PrevInvalid
+	if len(rrule.ByMonthDays) == 0 && len(rrule.ByWeekdays) == 0 {
+		ib = rrule.InvalidBehavior
+	}
+
+	months := 0
+
 	return &iterator{
 		minTime:  start,
 		maxTime:  timeOrMax(rrule.Until),
 		setpos:   rrule.BySetPos,
 		queueCap: rrule.Count,
 		next: func() *time.Time {
-			ret := current // copy current
-			current = current.AddDate(0, interval, 0)
-			return &ret
-		},
-
-		valid: func(t *time.Time) bool {
-			if t == nil {
-				return false
-			}
-			if len(rrule.ByMonthDays) > 0 {
-				return checkLimiters(t,
-					validMonth(rrule.ByMonths),
-					validWeekday(rrule.ByWeekdays),
-				)
+			for {
+				ret, ok := addMonths(start, months, ib)
+				months += interval
+				if ok {
+					return &ret
+				}
 			}
-			return checkLimiters(t,
-				validMonth(rrule.ByMonths),
-			)
 		},
 
+		valid: combineLimiters(
+			validMonth(rrule.ByMonths),
+		),
+
 		variations: func(t *time.Time) []time.Time {
 			if t == nil {
 				return nil
@@ -369,10 +592,14 @@ func setMonthly(rrule RRule) *iterator {
 			tt = expandByMinutes(tt, rrule.ByMinutes...)
 			tt = expandByHours(tt, rrule.ByHours...)
 			if len(rrule.ByMonthDays) > 0 {
-				tt = expandByMonthDays(tt, rrule.ByMonthDays...)
+				// BYDAY limits BYMONTHDAY rather than expanding the month.
+				// see note 2 on page 44 of RFC 5545.
+				tt = expandByMonthDays(tt, rrule.InvalidBehavior, rrule.ByMonthDays...)
+				tt = limitByWeekdays(tt, rrule.ByWeekdays)
 			} else if len(rrule.ByWeekdays) > 0 {
-				tt = expandMonthByWeekdays(tt, rrule.ib, rrule.BySetPos, rrule.ByWeekdays...)
+				tt = expandMonthByWeekdays(tt, rrule.InvalidBehavior, rrule.ByWeekdays...)
 			}
+			tt = limitBySetPos(tt, rrule.BySetPos)
 			return tt
 		},
 	}
@@ -404,6 +631,7 @@ func setDaily(rrule RRule) *iterator {
 
 		valid: combineLimiters(
 			validMonth(rrule.ByMonths),
+			validYearDay(rrule.ByYearDays),
 			validMonthDay(rrule.ByMonthDays),
 			validWeekday(rrule.ByWeekdays),
 		),
@@ -445,9 +673,7 @@ func setWeekly(rrule RRule) *iterator {
 			return &ret
 		},
 
-		valid: combineLimiters(
-			validMonth(rrule.ByMonths),
-		),
+		valid: combineLimiters(),
 
 		variations: func(t *time.Time) []time.Time {
 			if t == nil {
@@ -456,8 +682,11 @@ func setWeekly(rrule RRule) *iterator {
 			tt := expandBySeconds([]time.Time{*t}, rrule.BySeconds...)
 			tt = expandByMinutes(tt, rrule.ByMinutes...)
 			tt = expandByHours(tt, rrule.ByHours...)
-			tt = limitBySetPos(tt, rrule.BySetPos)
 			tt = expandByWeekdays(tt, rrule.weekStart(), rrule.ByWeekdays...)
+			// a week may span two months, so BYMONTH limits each day rather
+			// than the week
+			tt = limitByMonths(tt, rrule.ByMonths)
+			tt = limitBySetPos(tt, rrule.BySetPos)
 			return tt
 		},
 	}
@@ -474,7 +703,14 @@ func setYearly(rrule RRule) *iterator {
 		interval = rrule.Interval
 	}
 
-	current := start
+	// as for MONTHLY, February 29th of Dtstart only matters when no BYxxx
+	// part sets the day.
+	ib := PrevInvalid
+	if len(rrule.ByMonthDays) == 0 && len(rrule.ByYearDays) == 0 && len(rrule.ByWeekdays) == 0 && len(rrule.ByWeekNumbers) == 0 {
+		ib = rrule.InvalidBehavior
+	}
+
+	months := 0
 
 	return &iterator{
 		minTime:  start,
@@ -482,29 +718,19 @@ func setYearly(rrule RRule) *iterator {
 		setpos:   rrule.BySetPos,
 		queueCap: rrule.Count,
 		next: func() *time.Time {
-			ret := current // copy current
-			current = current.AddDate(interval, 0, 0)
-			return &ret
-		},
-
-		valid: func(t *time.Time) bool {
-			if t == nil {
-				return false
-			}
-
-			// see note 2 on page 44 of RFC 5545, including erratum 3747.
-			if len(rrule.ByYearDays) > 0 || len(rrule.ByMonthDays) > 0 {
-				return checkLimiters(t,
-					validMonth(rrule.ByMonths),
-					validWeekday(rrule.ByWeekdays),
-				)
+			for {
+				ret, ok := addMonths(start, months, ib)
+				months += 12 * interval
+				if ok {
+					return &ret
+				}
 			}
-
-			return checkLimiters(t,
-				validMonth(rrule.ByMonths),
-			)
 		},
 
+		// BYMONTH picks the months of the year, so the month of the key time
+		// doesn't matter; the expanded days are limited instead.
+		valid: combineLimiters(),
+
 		variations: func(t *time.Time) []time.Time {
 			if t == nil {
 				return nil
@@ -514,18 +740,48 @@ func setYearly(rrule RRule) *iterator {
 			tt = expandByMinutes(tt, rrule.ByMinutes...)
 			tt = expandByHours(tt, rrule.ByHours...)
 
-			tt = expandByMonthDays(tt, rrule.ByMonthDays...)
-			tt = expandByYearDays(tt, rrule.ByYearDays...)
-			tt = expandByWeekNumbers(tt, rrule.weekStart(), rrule.ByWeekNumbers...)
-			tt = expandByMonths(tt, rrule.ib, rrule.ByMonths...)
-
-			// see note 2 on page 44 of RFC 5545, including erratum 3779.
-			if len(rrule.ByYearDays) == 0 && len(rrule.ByMonthDays) == 0 {
-				if len(rrule.ByMonths) != 0 {
-					tt = expandMonthByWeekdays(tt, rrule.ib, nil, rrule.ByWeekdays...)
-				} else {
-					tt = expandYearByWeekdays(tt, rrule.ib, rrule.ByWeekdays...)
+			// BYWEEKNO expands to whole weeks, which may cross into other
+			// months, so BYMONTH, BYYEARDAY, BYMONTHDAY, and BYDAY limit the
+			// expanded days instead.
+			if len(rrule.ByWeekNumbers) > 0 {
+				tt = expandByWeekNumbers(tt, rrule.weekStart(), rrule.ByWeekNumbers...)
+				tt = limitByMonths(tt, rrule.ByMonths)
+				tt = limitByYearDays(tt, rrule.ByYearDays)
+				tt = limitByMonthDays(tt, rrule.ByMonthDays)
+				tt = limitByWeekdays(tt, rrule.ByWeekdays)
+				tt = limitBySetPos(tt, rrule.BySetPos)
+				return tt
+			}
+
+			if len(rrule.ByYearDays) > 0 {
+				tt = expandByYearDays(tt, rrule.ByYearDays...)
+				tt = limitByMonths(tt, rrule.ByMonths)
+				tt = limitByMonthDays(tt, rrule.ByMonthDays)
+			} else if len(rrule.ByMonthDays) > 0 && len(rrule.ByMonths) > 0 {
+				// BYMONTHDAY counts within each month of BYMONTH, so negative
+				// days are resolved against that month rather than the month
+				// of the key time
+				for i, t := range tt {
+					tt[i] = firstOfMonth(t)
 				}
+				tt = expandByMonths(tt, rrule.InvalidBehavior, rrule.ByMonths...)
+				tt = expandByMonthDays(tt, rrule.InvalidBehavior, rrule.ByMonthDays...)
+			} else {
+				tt = expandByMonthDays(tt, rrule.InvalidBehavior, rrule.ByMonthDays...)
+				tt = expandByMonths(tt, rrule.InvalidBehavior, rrule.ByMonths...)
+			}
+
+			// see note 2 on page 44 of RFC 5545, including errata 3747 and
+			// 3779: BYDAY limits BYYEARDAY and BYMONTHDAY, and otherwise
+			// expands within each month of BYMONTH, or the whole year, so an
+			// nth weekday counts within the month when BYMONTH is present.
+			switch {
+			case len(rrule.ByYearDays) > 0 || len(rrule.ByMonthDays) > 0:
+				tt = limitByWeekdays(tt, rrule.ByWeekdays)
+			case len(rrule.ByMonths) != 0:
+				tt = expandMonthByWeekdays(tt, rrule.InvalidBehavior, rrule.ByWeekdays...)
+			default:
+				tt = expandYearByWeekdays(tt, rrule.InvalidBehavior, rrule.ByWeekdays...)
 			}
 
 			tt = limitBySetPos(tt, rrule.BySetPos)
@@ -534,13 +790,83 @@ func setYearly(rrule RRule) *iterator {
 	}
 }
 
+// Clone returns a copy of the pattern that shares no memory with it, so
+// either can be changed without affecting the other.
+func (rrule RRule) Clone() RRule {
+	rrule.BySeconds = cloneInts(rrule.BySeconds)
+	rrule.ByMinutes = cloneInts(rrule.ByMinutes)
+	rrule.ByHours = cloneInts(rrule.ByHours)
+	rrule.ByMonthDays = cloneInts(rrule.ByMonthDays)
+	rrule.ByWeekNumbers = cloneInts(rrule.ByWeekNumbers)
+	rrule.ByYearDays = cloneInts(rrule.ByYearDays)
+	rrule.BySetPos = cloneInts(rrule.BySetPos)
+
+	if rrule.ByWeekdays != nil {
+		rrule.ByWeekdays = append([]QualifiedWeekday{}, rrule.ByWeekdays...)
+	}
+	if rrule.ByMonths != nil {
+		rrule.ByMonths = append([]time.Month{}, rrule.ByMonths...)
+	}
+	if rrule.WeekStart != nil {
+		wd := *rrule.WeekStart
+		rrule.WeekStart = &wd
+	}
+
+	return rrule
+}
+
+func cloneInts(ints []int) []int {
+	if ints == nil {
+		return nil
+	}
+	return append([]int{}, ints...)
+}
+
+// Location returns the location the pattern is evaluated in, which is the
+// location of Dtstart. If Dtstart is zero, time.Local is returned, matching
+// the time.Now used when an iterator is generated.
+func (rrule RRule) Location() *time.Location {
+	if rrule.Dtstart.IsZero() {
+		return time.Local
+	}
+	return rrule.Dtstart.Location()
+}
+
+// DefaultWeekStart is the first day of the week when WKST is absent, as RFC
+// 5545 specifies.
+const DefaultWeekStart = time.Monday
+
+// weekStart returns the first day of the week, resolving a nil WeekStart to
+// DefaultWeekStart. Everything that depends on WKST, from the weeks of a
+// WEEKLY interval to BYWEEKNO, must use it rather than WeekStart, whose zero
+// value would otherwise be mistaken for Sunday.
 func (rrule *RRule) weekStart() time.Weekday {
 	if rrule.WeekStart == nil {
-		return time.Monday
+		return DefaultWeekStart
 	}
 	return *rrule.WeekStart
 }
 
+// addMonths returns the time months after t, on the same day of the month.
+// If that day doesn't exist, ib decides the result: the last day of the
+// month for PrevInvalid, the first day of the following month for
+// NextInvalid, or false for OmitInvalid.
+func addMonths(t time.Time, months int, ib InvalidBehavior) (time.Time, bool) {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if days := daysInMonth(first); t.Day() > days {
+		switch ib {
+		case PrevInvalid:
+			return first.AddDate(0, 0, days-1), true
+		case NextInvalid:
+			return first.AddDate(0, 1, 0), true
+		default:
+			return time.Time{}, false
+		}
+	}
+
+	return first.AddDate(0, 0, t.Day()-1), true
+}
+
 func timeOrMax(t time.Time) time.Time {
 	if t.IsZero() {
 		return absoluteMaxTime
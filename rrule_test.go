@@ -1,6 +1,7 @@
 package rrule
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -78,6 +79,33 @@ var cases = []struct {
 		Dates:    []string{"2018-08-25T09:09:01Z", "2018-08-25T09:09:02Z", "2018-08-25T09:09:03Z", "2018-08-25T09:10:01Z"},
 		Terminal: true,
 	},
+	{
+		Name:   "secondly setpos beyond the period",
+		String: "FREQ=SECONDLY;COUNT=4;BYMINUTE=0;BYSETPOS=30,-1",
+		RRule: RRule{
+			Frequency: Secondly,
+			Count:     4,
+			Dtstart:   now.Truncate(time.Second),
+			ByMinutes: []int{0},
+			BySetPos:  []int{30, -1},
+		},
+		Dates:    []string{"2018-08-25T10:00:00Z", "2018-08-25T10:00:01Z", "2018-08-25T10:00:02Z", "2018-08-25T10:00:03Z"},
+		Terminal: true,
+	},
+	{
+		Name:   "secondly setpos large negative",
+		String: "FREQ=SECONDLY;COUNT=3;INTERVAL=7;BYMINUTE=10;BYSETPOS=-30,-1",
+		RRule: RRule{
+			Frequency: Secondly,
+			Count:     3,
+			Interval:  7,
+			Dtstart:   now.Truncate(time.Second),
+			ByMinutes: []int{10},
+			BySetPos:  []int{-30, -1},
+		},
+		Dates:    []string{"2018-08-25T09:10:06Z", "2018-08-25T09:10:13Z", "2018-08-25T09:10:20Z"},
+		Terminal: true,
+	},
 	{
 		Name: "minutely setpos",
 		RRule: RRule{
@@ -158,119 +186,714 @@ var cases = []struct {
 			ByMonths:   []time.Month{time.August, time.September},
 			BySetPos:   []int{1, 3, -1},
 		},
-		Dates:    []string{"2018-09-30T09:08:07Z", "2019-08-01T09:08:07Z", "2019-08-03T09:08:07Z", "2019-09-30T09:08:07Z"},
+		Dates:    []string{"2018-09-30T09:08:07Z", "2019-08-01T09:08:07Z", "2019-08-03T09:08:07Z", "2019-09-30T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "weekly setpos with weekdays",
+		String: "FREQ=WEEKLY;COUNT=3;BYDAY=MO,FR;BYSETPOS=-1",
+		RRule: RRule{
+			Frequency:  Weekly,
+			Count:      3,
+			Dtstart:    now,
+			ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Friday}},
+			BySetPos:   []int{-1},
+		},
+		Dates:    []string{"2018-08-31T09:08:07Z", "2018-09-07T09:08:07Z", "2018-09-14T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "monthly setpos with hours",
+		String: "FREQ=MONTHLY;COUNT=3;BYHOUR=9,17;BYDAY=MO,TU;BYSETPOS=-1",
+		RRule: RRule{
+			Frequency:  Monthly,
+			Count:      3,
+			Dtstart:    now,
+			ByHours:    []int{9, 17},
+			ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Tuesday}},
+			BySetPos:   []int{-1},
+		},
+		Dates:    []string{"2018-08-28T17:08:07Z", "2018-09-25T17:08:07Z", "2018-10-30T17:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "monthly setpos with monthdays",
+		String: "FREQ=MONTHLY;COUNT=3;BYMONTHDAY=1,15;BYSETPOS=-1",
+		RRule: RRule{
+			Frequency:   Monthly,
+			Count:       3,
+			Dtstart:     now,
+			ByMonthDays: []int{1, 15},
+			BySetPos:    []int{-1},
+		},
+		Dates:    []string{"2018-09-15T09:08:07Z", "2018-10-15T09:08:07Z", "2018-11-15T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly nth weekday of month",
+		String: "FREQ=YEARLY;COUNT=3;BYDAY=3SU;BYMONTH=11",
+		RRule: RRule{
+			Frequency:  Yearly,
+			Count:      3,
+			Dtstart:    now,
+			ByWeekdays: []QualifiedWeekday{{N: 3, WD: time.Sunday}},
+			ByMonths:   []time.Month{time.November},
+		},
+		Dates:    []string{"2018-11-18T09:08:07Z", "2019-11-17T09:08:07Z", "2020-11-15T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly last weekday of months",
+		String: "FREQ=YEARLY;COUNT=4;BYDAY=-1FR;BYMONTH=3,11",
+		RRule: RRule{
+			Frequency:  Yearly,
+			Count:      4,
+			Dtstart:    now,
+			ByWeekdays: []QualifiedWeekday{{N: -1, WD: time.Friday}},
+			ByMonths:   []time.Month{time.March, time.November},
+		},
+		Dates:    []string{"2018-11-30T09:08:07Z", "2019-03-29T09:08:07Z", "2019-11-29T09:08:07Z", "2020-03-27T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly monthday of another month",
+		String: "FREQ=YEARLY;COUNT=3;BYMONTHDAY=1;BYMONTH=11",
+		RRule: RRule{
+			Frequency:   Yearly,
+			Count:       3,
+			Dtstart:     now,
+			ByMonthDays: []int{1},
+			ByMonths:    []time.Month{time.November},
+		},
+		Dates:    []string{"2018-11-01T09:08:07Z", "2019-11-01T09:08:07Z", "2020-11-01T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly yeardays limited by month",
+		String: "FREQ=YEARLY;COUNT=3;BYYEARDAY=1,100;BYMONTH=4",
+		RRule: RRule{
+			Frequency:  Yearly,
+			Count:      3,
+			Dtstart:    now,
+			ByMonths:   []time.Month{time.April},
+			ByYearDays: []int{1, 100},
+		},
+		Dates:    []string{"2019-04-10T09:08:07Z", "2020-04-09T09:08:07Z", "2021-04-10T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly yeardays limited by weekday",
+		String: "FREQ=YEARLY;COUNT=3;BYDAY=MO;BYYEARDAY=1,100",
+		RRule: RRule{
+			Frequency:  Yearly,
+			Count:      3,
+			Dtstart:    now,
+			ByWeekdays: []QualifiedWeekday{{WD: time.Monday}},
+			ByYearDays: []int{1, 100},
+		},
+		Dates:    []string{"2023-04-10T09:08:07Z", "2024-01-01T09:08:07Z", "2029-01-01T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "hourly interval across days",
+		String: "FREQ=HOURLY;COUNT=4;INTERVAL=6",
+		RRule: RRule{
+			Frequency: Hourly,
+			Count:     4,
+			Interval:  6,
+			Dtstart:   time.Date(2018, 8, 25, 21, 0, 0, 0, time.UTC),
+		},
+		Dates:    []string{"2018-08-25T21:00:00Z", "2018-08-26T03:00:00Z", "2018-08-26T09:00:00Z", "2018-08-26T15:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "minutely interval across days",
+		String: "FREQ=MINUTELY;COUNT=4;INTERVAL=90",
+		RRule: RRule{
+			Frequency: Minutely,
+			Count:     4,
+			Interval:  90,
+			Dtstart:   time.Date(2018, 8, 25, 21, 0, 0, 0, time.UTC),
+		},
+		Dates:    []string{"2018-08-25T21:00:00Z", "2018-08-25T22:30:00Z", "2018-08-26T00:00:00Z", "2018-08-26T01:30:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "hourly interval with minutes",
+		String: "FREQ=HOURLY;COUNT=6;INTERVAL=5;BYMINUTE=0,30",
+		RRule: RRule{
+			Frequency: Hourly,
+			Count:     6,
+			Interval:  5,
+			Dtstart:   time.Date(2018, 8, 25, 21, 0, 0, 0, time.UTC),
+			ByMinutes: []int{0, 30},
+		},
+		Dates:    []string{"2018-08-25T21:00:00Z", "2018-08-25T21:30:00Z", "2018-08-26T02:00:00Z", "2018-08-26T02:30:00Z", "2018-08-26T07:00:00Z", "2018-08-26T07:30:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "minutely interval limited by hours",
+		String: "FREQ=MINUTELY;COUNT=4;INTERVAL=90;BYMINUTE=0,30;BYHOUR=0,3,6",
+		RRule: RRule{
+			Frequency: Minutely,
+			Count:     4,
+			Interval:  90,
+			Dtstart:   time.Date(2018, 8, 25, 21, 0, 0, 0, time.UTC),
+			ByMinutes: []int{0, 30},
+			ByHours:   []int{0, 3, 6},
+		},
+		Dates:    []string{"2018-08-26T00:00:00Z", "2018-08-26T03:00:00Z", "2018-08-26T06:00:00Z", "2018-08-27T00:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "daily by hour before dtstart",
+		String: "FREQ=DAILY;COUNT=3;BYHOUR=8",
+		RRule: RRule{
+			Frequency: Daily,
+			Count:     3,
+			Dtstart:   now,
+			ByHours:   []int{8},
+		},
+		Dates:    []string{"2018-08-26T08:08:07Z", "2018-08-27T08:08:07Z", "2018-08-28T08:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "daily by hours around dtstart",
+		String: "FREQ=DAILY;COUNT=3;BYHOUR=8,10",
+		RRule: RRule{
+			Frequency: Daily,
+			Count:     3,
+			Dtstart:   now,
+			ByHours:   []int{8, 10},
+		},
+		Dates:    []string{"2018-08-25T10:08:07Z", "2018-08-26T08:08:07Z", "2018-08-26T10:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "daily by minute before dtstart",
+		String: "FREQ=DAILY;COUNT=2;BYMINUTE=0;BYHOUR=9",
+		RRule: RRule{
+			Frequency: Daily,
+			Count:     2,
+			Dtstart:   now,
+			ByHours:   []int{9},
+			ByMinutes: []int{0},
+		},
+		Dates:    []string{"2018-08-26T09:00:07Z", "2018-08-27T09:00:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name: "daily until",
+		RRule: RRule{
+			Frequency: Daily,
+			Until:     time.Date(2018, 8, 30, 0, 0, 0, 0, time.UTC),
+			Dtstart:   now,
+		},
+		Dates:    []string{"2018-08-25T09:08:07Z", "2018-08-26T09:08:07Z", "2018-08-27T09:08:07Z", "2018-08-28T09:08:07Z", "2018-08-29T09:08:07Z"},
+		Terminal: true,
+		String:   "FREQ=DAILY;UNTIL=20180830T000000Z",
+	},
+
+	{
+		Name: "daily until floating",
+		RRule: RRule{
+			Frequency:     Daily,
+			Until:         time.Date(2018, 8, 30, 0, 0, 0, 0, time.UTC),
+			UntilFloating: true,
+			Dtstart:       now,
+		},
+		Dates:    []string{"2018-08-25T09:08:07Z", "2018-08-26T09:08:07Z", "2018-08-27T09:08:07Z", "2018-08-28T09:08:07Z", "2018-08-29T09:08:07Z"},
+		Terminal: true,
+		String:   "FREQ=DAILY;UNTIL=20180830T000000",
+	},
+
+	{
+		Name: "daily until date",
+		RRule: RRule{
+			Frequency:     Daily,
+			Until:         time.Date(2018, 8, 30, 0, 0, 0, 0, time.UTC),
+			UntilFloating: true,
+			UntilDate:     true,
+			Dtstart:       now,
+		},
+		// a date UNTIL includes every instance on that day
+		Dates:    []string{"2018-08-25T09:08:07Z", "2018-08-26T09:08:07Z", "2018-08-27T09:08:07Z", "2018-08-28T09:08:07Z", "2018-08-29T09:08:07Z", "2018-08-30T09:08:07Z"},
+		Terminal: true,
+		String:   "FREQ=DAILY;UNTIL=20180830",
+
+		// teambition has no notion of a date UNTIL, so it stops at midnight
+		NoTeambitionComparison: true,
+	},
+
+	{
+		Name: "simple monthly",
+		RRule: RRule{
+			Frequency: Monthly,
+			Count:     3,
+			Dtstart:   now,
+		},
+		Dates:    []string{"2018-08-25T09:08:07Z", "2018-09-25T09:08:07Z", "2018-10-25T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name: "long monthly",
+		RRule: RRule{
+			Frequency: Monthly,
+			Count:     300,
+			Dtstart:   now,
+		},
+		Terminal: true,
+		NoTest:   true,
+	},
+
+	{
+		Name: "long daily",
+		RRule: RRule{
+			Frequency: Daily,
+			Count:     1000,
+			Dtstart:   now,
+		},
+		Terminal: true,
+		NoTest:   true,
+	},
+
+	{
+		Name: "monthly by weekday",
+		RRule: RRule{
+			Frequency:  Monthly,
+			Count:      3,
+			Dtstart:    now,
+			ByWeekdays: []QualifiedWeekday{{N: 1, WD: time.Tuesday}},
+		},
+		Dates:    []string{"2018-09-04T09:08:07Z", "2018-10-02T09:08:07Z", "2018-11-06T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name: "simple weekly",
+		RRule: RRule{
+			Frequency: Weekly,
+			Count:     3,
+			Dtstart:   now,
+		},
+		Dates:    []string{"2018-08-25T09:08:07Z", "2018-09-01T09:08:07Z", "2018-09-08T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "weekly by weekday",
+		String: "FREQ=WEEKLY;COUNT=3;BYDAY=TU",
+		RRule: RRule{
+			Frequency:  Weekly,
+			Count:      3,
+			Dtstart:    now,
+			ByWeekdays: []QualifiedWeekday{{WD: time.Tuesday}},
+		},
+		Dates:    []string{"2018-08-28T09:08:07Z", "2018-09-04T09:08:07Z", "2018-09-11T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "weekly by weekday limited by month",
+		String: "FREQ=WEEKLY;COUNT=3;BYDAY=TU;BYMONTH=9",
+		RRule: RRule{
+			Frequency:  Weekly,
+			Count:      3,
+			Dtstart:    now,
+			ByWeekdays: []QualifiedWeekday{{WD: time.Tuesday}},
+			ByMonths:   []time.Month{time.September},
+		},
+		Dates:    []string{"2018-09-04T09:08:07Z", "2018-09-11T09:08:07Z", "2018-09-18T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "weekly setpos limited by month",
+		String: "FREQ=WEEKLY;COUNT=3;BYDAY=TH;BYMONTH=8;BYSETPOS=1",
+		RRule: RRule{
+			Frequency:  Weekly,
+			Count:      3,
+			Dtstart:    now,
+			ByWeekdays: []QualifiedWeekday{{WD: time.Thursday}},
+			ByMonths:   []time.Month{time.August},
+			BySetPos:   []int{1},
+		},
+		Dates:    []string{"2018-08-30T09:08:07Z", "2019-08-01T09:08:07Z", "2019-08-08T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name: "ten years every fourth week",
+		RRule: RRule{
+			Frequency:  Weekly,
+			Interval:   4,
+			Until:      time.Date(2028, 8, 25, 0, 0, 0, 0, time.UTC),
+			Dtstart:    now,
+			ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Thursday}},
+		},
+		Terminal: true,
+		NoTest:   true,
+	},
+
+	{
+		Name:   "weekly by weekday starting mid-week",
+		String: "FREQ=WEEKLY;COUNT=5;BYDAY=MO,WE,FR",
+		RRule: RRule{
+			Frequency:  Weekly,
+			Count:      5,
+			Dtstart:    time.Date(2018, 8, 22, 9, 8, 7, 0, time.UTC), // a wednesday
+			ByWeekdays: []QualifiedWeekday{{WD: time.Monday}, {WD: time.Wednesday}, {WD: time.Friday}},
+		},
+		Dates:    []string{"2018-08-22T09:08:07Z", "2018-08-24T09:08:07Z", "2018-08-27T09:08:07Z", "2018-08-29T09:08:07Z", "2018-08-31T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "weekly by unordered weekdays starting mid-week",
+		String: "FREQ=WEEKLY;COUNT=5;BYDAY=FR,MO,WE",
+		RRule: RRule{
+			Frequency:  Weekly,
+			Count:      5,
+			Dtstart:    time.Date(2018, 8, 22, 9, 8, 7, 0, time.UTC), // a wednesday
+			ByWeekdays: []QualifiedWeekday{{WD: time.Friday}, {WD: time.Monday}, {WD: time.Wednesday}},
+		},
+		Dates:    []string{"2018-08-22T09:08:07Z", "2018-08-24T09:08:07Z", "2018-08-27T09:08:07Z", "2018-08-29T09:08:07Z", "2018-08-31T09:08:07Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "rfc: Every other week on Tuesday and Sunday, week starting on Monday",
+		String: "FREQ=WEEKLY;COUNT=4;INTERVAL=2;BYDAY=TU,SU;WKST=MO",
+		RRule: RRule{
+			Frequency:  Weekly,
+			Count:      4,
+			Interval:   2,
+			Dtstart:    time.Date(1997, time.August, 5, 9, 0, 0, 0, NewYork()),
+			ByWeekdays: []QualifiedWeekday{{WD: time.Tuesday}, {WD: time.Sunday}},
+			WeekStart:  weekdayPtr(time.Monday),
+		},
+		Dates:    []string{"1997-08-05T09:00:00-04:00", "1997-08-10T09:00:00-04:00", "1997-08-19T09:00:00-04:00", "1997-08-24T09:00:00-04:00"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "rfc: Every other week on Tuesday and Sunday, week starting on Sunday",
+		String: "FREQ=WEEKLY;COUNT=4;INTERVAL=2;BYDAY=TU,SU;WKST=SU",
+		RRule: RRule{
+			Frequency:  Weekly,
+			Count:      4,
+			Interval:   2,
+			Dtstart:    time.Date(1997, time.August, 5, 9, 0, 0, 0, NewYork()),
+			ByWeekdays: []QualifiedWeekday{{WD: time.Tuesday}, {WD: time.Sunday}},
+			WeekStart:  weekdayPtr(time.Sunday),
+		},
+		Dates:    []string{"1997-08-05T09:00:00-04:00", "1997-08-17T09:00:00-04:00", "1997-08-19T09:00:00-04:00", "1997-08-31T09:00:00-04:00"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "every other week on Sunday and Monday, week starting on Monday",
+		String: "FREQ=WEEKLY;COUNT=4;INTERVAL=2;BYDAY=SU,MO;WKST=MO",
+		RRule: RRule{
+			Frequency:  Weekly,
+			Count:      4,
+			Interval:   2,
+			Dtstart:    time.Date(2018, time.August, 27, 9, 0, 0, 0, time.UTC),
+			ByWeekdays: []QualifiedWeekday{{WD: time.Sunday}, {WD: time.Monday}},
+			WeekStart:  weekdayPtr(time.Monday),
+		},
+		Dates:    []string{"2018-08-27T09:00:00Z", "2018-09-02T09:00:00Z", "2018-09-10T09:00:00Z", "2018-09-16T09:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "every other week on Sunday and Monday, week starting on Sunday",
+		String: "FREQ=WEEKLY;COUNT=4;INTERVAL=2;BYDAY=SU,MO;WKST=SU",
+		RRule: RRule{
+			Frequency:  Weekly,
+			Count:      4,
+			Interval:   2,
+			Dtstart:    time.Date(2018, time.August, 27, 9, 0, 0, 0, time.UTC),
+			ByWeekdays: []QualifiedWeekday{{WD: time.Sunday}, {WD: time.Monday}},
+			WeekStart:  weekdayPtr(time.Sunday),
+		},
+		Dates:    []string{"2018-08-27T09:00:00Z", "2018-09-09T09:00:00Z", "2018-09-10T09:00:00Z", "2018-09-23T09:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "every other week on Sunday and Monday, week starting by default",
+		String: "FREQ=WEEKLY;COUNT=4;INTERVAL=2;BYDAY=SU,MO",
+		RRule: RRule{
+			Frequency:  Weekly,
+			Count:      4,
+			Interval:   2,
+			Dtstart:    time.Date(2018, time.August, 27, 9, 0, 0, 0, time.UTC),
+			ByWeekdays: []QualifiedWeekday{{WD: time.Sunday}, {WD: time.Monday}},
+		},
+		Dates:    []string{"2018-08-27T09:00:00Z", "2018-09-02T09:00:00Z", "2018-09-10T09:00:00Z", "2018-09-16T09:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly by weekday",
+		String: "FREQ=YEARLY;COUNT=4;BYDAY=TU,35WE,-17MO",
+		RRule: RRule{
+			Frequency:  Yearly,
+			Count:      4,
+			Dtstart:    now,
+			ByWeekdays: []QualifiedWeekday{{WD: time.Tuesday}, {N: 35, WD: time.Wednesday}, {N: -17, WD: time.Monday}},
+		},
+		Dates:    []string{"2018-08-28T09:08:07Z", "2018-08-29T09:08:07Z", "2018-09-04T09:08:07Z", "2018-09-10T09:08:07Z"},
+		Terminal: true,
+
+		// I'm not sure if I'm reading the spec wrong or if they have a bug, but they return no results.
+		// lib-recur agrees with my implementation.
+		NoTeambitionComparison: true,
+	},
+
+	{
+		Name:   "rfc: Monday of week number 20",
+		String: "FREQ=YEARLY;COUNT=3;BYDAY=MO;BYWEEKNO=20",
+		RRule: RRule{
+			Frequency:     Yearly,
+			Count:         3,
+			Dtstart:       time.Date(1997, time.May, 12, 9, 0, 0, 0, NewYork()),
+			ByWeekNumbers: []int{20},
+			ByWeekdays:    []QualifiedWeekday{{WD: time.Monday}},
+		},
+		Dates:    []string{"1997-05-12T09:00:00-04:00", "1998-05-11T09:00:00-04:00", "1999-05-17T09:00:00-04:00"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly week 1 starting in december",
+		String: "FREQ=YEARLY;COUNT=5;BYDAY=MO;BYWEEKNO=1",
+		RRule: RRule{
+			Frequency:     Yearly,
+			Count:         5,
+			Dtstart:       time.Date(2014, time.June, 1, 9, 0, 0, 0, time.UTC),
+			ByWeekNumbers: []int{1},
+			ByWeekdays:    []QualifiedWeekday{{WD: time.Monday}},
+		},
+		Dates:    []string{"2014-12-29T09:00:00Z", "2016-01-04T09:00:00Z", "2017-01-02T09:00:00Z", "2018-01-01T09:00:00Z", "2018-12-31T09:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly week 1 with sunday week start",
+		String: "FREQ=YEARLY;COUNT=4;BYDAY=SU,SA;BYWEEKNO=1;WKST=SU",
+		RRule: RRule{
+			Frequency:     Yearly,
+			Count:         4,
+			Dtstart:       time.Date(2014, time.June, 1, 9, 0, 0, 0, time.UTC),
+			ByWeekNumbers: []int{1},
+			ByWeekdays:    []QualifiedWeekday{{WD: time.Sunday}, {WD: time.Saturday}},
+			WeekStart:     weekdayPtr(time.Sunday),
+		},
+		Dates:    []string{"2015-01-04T09:00:00Z", "2015-01-10T09:00:00Z", "2016-01-03T09:00:00Z", "2016-01-09T09:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly last week",
+		String: "FREQ=YEARLY;COUNT=3;BYDAY=MO;BYWEEKNO=-1",
+		RRule: RRule{
+			Frequency:     Yearly,
+			Count:         3,
+			Dtstart:       time.Date(2019, time.January, 1, 9, 0, 0, 0, time.UTC),
+			ByWeekNumbers: []int{-1},
+			ByWeekdays:    []QualifiedWeekday{{WD: time.Monday}},
+		},
+		Dates:    []string{"2019-12-23T09:00:00Z", "2020-12-28T09:00:00Z", "2021-12-27T09:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly week -53 only in long years",
+		String: "FREQ=YEARLY;COUNT=2;BYDAY=TH;BYWEEKNO=-53",
+		RRule: RRule{
+			Frequency:     Yearly,
+			Count:         2,
+			Dtstart:       time.Date(2019, time.January, 1, 9, 0, 0, 0, time.UTC),
+			ByWeekNumbers: []int{-53},
+			ByWeekdays:    []QualifiedWeekday{{WD: time.Thursday}},
+		},
+		Dates:    []string{"2020-01-02T09:00:00Z", "2026-01-01T09:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly week 1 limited to december",
+		String: "FREQ=YEARLY;COUNT=3;BYWEEKNO=1;BYMONTH=12",
+		RRule: RRule{
+			Frequency:     Yearly,
+			Count:         3,
+			Dtstart:       time.Date(2014, time.June, 1, 9, 0, 0, 0, time.UTC),
+			ByWeekNumbers: []int{1},
+			ByMonths:      []time.Month{time.December},
+		},
+		Dates:    []string{"2014-12-29T09:00:00Z", "2014-12-30T09:00:00Z", "2014-12-31T09:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly week 20 limited to the 15th",
+		String: "FREQ=YEARLY;COUNT=3;BYMONTHDAY=15;BYWEEKNO=20",
+		RRule: RRule{
+			Frequency:     Yearly,
+			Count:         3,
+			Dtstart:       time.Date(2021, time.January, 1, 9, 0, 0, 0, time.UTC),
+			ByWeekNumbers: []int{20},
+			ByMonthDays:   []int{15},
+		},
+		Dates:    []string{"2023-05-15T09:00:00Z", "2024-05-15T09:00:00Z", "2025-05-15T09:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly week 1 limited to the last day of the year",
+		String: "FREQ=YEARLY;COUNT=2;BYYEARDAY=-1;BYWEEKNO=1",
+		RRule: RRule{
+			Frequency:     Yearly,
+			Count:         2,
+			Dtstart:       time.Date(2014, time.June, 1, 9, 0, 0, 0, time.UTC),
+			ByWeekNumbers: []int{1},
+			ByYearDays:    []int{-1},
+		},
+		Dates:    []string{"2014-12-31T09:00:00Z", "2018-12-31T09:00:00Z"},
 		Terminal: true,
 	},
 
 	{
-		Name: "daily until",
+		Name: "monthly by monthday",
 		RRule: RRule{
-			Frequency: Daily,
-			Until:     time.Date(2018, 8, 30, 0, 0, 0, 0, time.UTC),
-			Dtstart:   now,
+			Frequency:   Monthly,
+			Count:       3,
+			Dtstart:     now,
+			ByMonthDays: []int{10},
 		},
-		Dates:    []string{"2018-08-25T09:08:07Z", "2018-08-26T09:08:07Z", "2018-08-27T09:08:07Z", "2018-08-28T09:08:07Z", "2018-08-29T09:08:07Z"},
+		Dates:    []string{"2018-09-10T09:08:07Z", "2018-10-10T09:08:07Z", "2018-11-10T09:08:07Z"},
 		Terminal: true,
-		String:   "FREQ=DAILY;UNTIL=20180830T000000Z",
 	},
 
 	{
-		Name: "daily until floating",
+		Name:   "yearly on february 29th skips common years",
+		String: "FREQ=YEARLY;COUNT=3",
 		RRule: RRule{
-			Frequency:     Daily,
-			Until:         time.Date(2018, 8, 30, 0, 0, 0, 0, time.UTC),
-			UntilFloating: true,
-			Dtstart:       now,
+			Frequency: Yearly,
+			Count:     3,
+			Dtstart:   time.Date(2020, time.February, 29, 9, 0, 0, 0, time.UTC),
 		},
-		Dates:    []string{"2018-08-25T09:08:07Z", "2018-08-26T09:08:07Z", "2018-08-27T09:08:07Z", "2018-08-28T09:08:07Z", "2018-08-29T09:08:07Z"},
+		Dates:    []string{"2020-02-29T09:00:00Z", "2024-02-29T09:00:00Z", "2028-02-29T09:00:00Z"},
 		Terminal: true,
-		String:   "FREQ=DAILY;UNTIL=20180830T000000",
 	},
 
 	{
-		Name: "simple monthly",
+		Name:   "monthly on the 31st of dtstart skips short months",
+		String: "FREQ=MONTHLY;COUNT=4",
 		RRule: RRule{
 			Frequency: Monthly,
-			Count:     3,
-			Dtstart:   now,
+			Count:     4,
+			Dtstart:   time.Date(2021, time.January, 31, 9, 0, 0, 0, time.UTC),
 		},
-		Dates:    []string{"2018-08-25T09:08:07Z", "2018-09-25T09:08:07Z", "2018-10-25T09:08:07Z"},
+		Dates:    []string{"2021-01-31T09:00:00Z", "2021-03-31T09:00:00Z", "2021-05-31T09:00:00Z", "2021-07-31T09:00:00Z"},
 		Terminal: true,
 	},
 
 	{
-		Name: "long monthly",
+		Name:   "monthly by monthday from the 31st",
+		String: "FREQ=MONTHLY;COUNT=3;BYMONTHDAY=15",
 		RRule: RRule{
-			Frequency: Monthly,
-			Count:     300,
-			Dtstart:   now,
+			Frequency:   Monthly,
+			Count:       3,
+			Dtstart:     time.Date(2021, time.January, 31, 9, 0, 0, 0, time.UTC),
+			ByMonthDays: []int{15},
 		},
+		Dates:    []string{"2021-02-15T09:00:00Z", "2021-03-15T09:00:00Z", "2021-04-15T09:00:00Z"},
 		Terminal: true,
-		NoTest:   true,
 	},
 
 	{
-		Name: "monthly by weekday",
+		Name:   "monthly on the last day through a leap year",
+		String: "FREQ=MONTHLY;COUNT=4;BYMONTHDAY=-1",
 		RRule: RRule{
-			Frequency:  Monthly,
-			Count:      3,
-			Dtstart:    now,
-			ByWeekdays: []QualifiedWeekday{{N: 1, WD: time.Tuesday}},
+			Frequency:   Monthly,
+			Count:       4,
+			Dtstart:     time.Date(2020, time.January, 1, 9, 0, 0, 0, time.UTC),
+			ByMonthDays: []int{-1},
 		},
-		Dates:    []string{"2018-09-04T09:08:07Z", "2018-10-02T09:08:07Z", "2018-11-06T09:08:07Z"},
+		Dates:    []string{"2020-01-31T09:00:00Z", "2020-02-29T09:00:00Z", "2020-03-31T09:00:00Z", "2020-04-30T09:00:00Z"},
 		Terminal: true,
 	},
 
 	{
-		Name: "simple weekly",
+		Name:   "monthly on the second to last day",
+		String: "FREQ=MONTHLY;COUNT=3;BYMONTHDAY=-2",
 		RRule: RRule{
-			Frequency: Weekly,
-			Count:     3,
-			Dtstart:   now,
+			Frequency:   Monthly,
+			Count:       3,
+			Dtstart:     time.Date(2019, time.January, 1, 9, 0, 0, 0, time.UTC),
+			ByMonthDays: []int{-2},
 		},
-		Dates:    []string{"2018-08-25T09:08:07Z", "2018-09-01T09:08:07Z", "2018-09-08T09:08:07Z"},
+		Dates:    []string{"2019-01-30T09:00:00Z", "2019-02-27T09:00:00Z", "2019-03-30T09:00:00Z"},
 		Terminal: true,
 	},
 
 	{
-		Name:   "weekly by weekday",
-		String: "FREQ=WEEKLY;COUNT=3;BYDAY=TU",
+		Name:   "monthly on the 31st skips short months",
+		String: "FREQ=MONTHLY;COUNT=3;BYMONTHDAY=31",
 		RRule: RRule{
-			Frequency:  Weekly,
-			Count:      3,
-			Dtstart:    now,
-			ByWeekdays: []QualifiedWeekday{{WD: time.Tuesday}},
+			Frequency:   Monthly,
+			Count:       3,
+			Dtstart:     time.Date(2019, time.January, 1, 9, 0, 0, 0, time.UTC),
+			ByMonthDays: []int{31},
 		},
-		Dates:    []string{"2018-08-28T09:08:07Z", "2018-09-04T09:08:07Z", "2018-09-11T09:08:07Z"},
+		Dates:    []string{"2019-01-31T09:00:00Z", "2019-03-31T09:00:00Z", "2019-05-31T09:00:00Z"},
 		Terminal: true,
 	},
 
 	{
-		Name:   "yearly by weekday",
-		String: "FREQ=YEARLY;COUNT=4;BYDAY=TU,35WE,-17MO",
+		Name:   "daily limited to the last day of the month",
+		String: "FREQ=DAILY;COUNT=3;BYMONTHDAY=-1",
 		RRule: RRule{
-			Frequency:  Yearly,
-			Count:      4,
-			Dtstart:    now,
-			ByWeekdays: []QualifiedWeekday{{WD: time.Tuesday}, {N: 35, WD: time.Wednesday}, {N: -17, WD: time.Monday}},
+			Frequency:   Daily,
+			Count:       3,
+			Dtstart:     time.Date(2020, time.February, 1, 9, 0, 0, 0, time.UTC),
+			ByMonthDays: []int{-1},
 		},
-		Dates:    []string{"2018-08-28T09:08:07Z", "2018-08-29T09:08:07Z", "2018-09-04T09:08:07Z", "2018-09-10T09:08:07Z"},
+		Dates:    []string{"2020-02-29T09:00:00Z", "2020-03-31T09:00:00Z", "2020-04-30T09:00:00Z"},
 		Terminal: true,
-
-		// I'm not sure if I'm reading the spec wrong or if they have a bug, but they return no results.
-		// lib-recur agrees with my implementation.
-		NoTeambitionComparison: true,
 	},
 
 	{
-		Name: "monthly by monthday",
+		Name:   "rfc: Every Friday the 13th",
+		String: "FREQ=MONTHLY;COUNT=5;BYDAY=FR;BYMONTHDAY=13",
 		RRule: RRule{
 			Frequency:   Monthly,
-			Count:       3,
-			Dtstart:     now,
-			ByMonthDays: []int{10},
+			Count:       5,
+			Dtstart:     time.Date(1997, time.September, 2, 9, 0, 0, 0, NewYork()),
+			ByWeekdays:  []QualifiedWeekday{{WD: time.Friday}},
+			ByMonthDays: []int{13},
 		},
-		Dates:    []string{"2018-09-10T09:08:07Z", "2018-10-10T09:08:07Z", "2018-11-10T09:08:07Z"},
+		Dates:    []string{"1998-02-13T09:00:00-05:00", "1998-03-13T09:00:00-05:00", "1998-11-13T09:00:00-05:00", "1999-08-13T09:00:00-04:00", "2000-10-13T09:00:00-04:00"},
 		Terminal: true,
 	},
 
@@ -323,6 +946,125 @@ var cases = []struct {
 		Terminal: true,
 	},
 
+	{
+		Name:   "yearly by yearday across 1900",
+		String: "FREQ=YEARLY;COUNT=5;BYYEARDAY=60",
+		RRule: RRule{
+			Frequency:  Yearly,
+			Count:      5,
+			Dtstart:    time.Date(1898, time.January, 1, 12, 0, 0, 0, time.UTC),
+			ByYearDays: []int{60},
+		},
+		// 1900 is not a leap year, so day 60 is March 1st every year
+		Dates:    []string{"1898-03-01T12:00:00Z", "1899-03-01T12:00:00Z", "1900-03-01T12:00:00Z", "1901-03-01T12:00:00Z", "1902-03-01T12:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly by last day of leap years in the 1800s",
+		String: "FREQ=YEARLY;COUNT=3;BYYEARDAY=366",
+		RRule: RRule{
+			Frequency:  Yearly,
+			Count:      3,
+			Dtstart:    time.Date(1895, time.January, 1, 12, 0, 0, 0, time.UTC),
+			ByYearDays: []int{366},
+		},
+		Dates:    []string{"1896-12-31T12:00:00Z", "1904-12-31T12:00:00Z", "1908-12-31T12:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly by last yearday before 1900",
+		String: "FREQ=YEARLY;COUNT=3;BYYEARDAY=-1",
+		RRule: RRule{
+			Frequency:  Yearly,
+			Count:      3,
+			Dtstart:    time.Date(1899, time.January, 1, 12, 0, 0, 0, time.UTC),
+			ByYearDays: []int{-1},
+		},
+		Dates:    []string{"1899-12-31T12:00:00Z", "1900-12-31T12:00:00Z", "1901-12-31T12:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly by last yearday across a leap year",
+		String: "FREQ=YEARLY;COUNT=3;BYYEARDAY=-1,-366",
+		RRule: RRule{
+			Frequency:  Yearly,
+			Count:      3,
+			Dtstart:    time.Date(2019, time.January, 1, 9, 0, 0, 0, time.UTC),
+			ByYearDays: []int{-1, -366},
+		},
+		Dates:    []string{"2019-12-31T09:00:00Z", "2020-01-01T09:00:00Z", "2020-12-31T09:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "daily limited to the last yearday",
+		String: "FREQ=DAILY;COUNT=2;BYYEARDAY=-1",
+		RRule: RRule{
+			Frequency:  Daily,
+			Count:      2,
+			Dtstart:    time.Date(2019, time.December, 1, 9, 0, 0, 0, time.UTC),
+			ByYearDays: []int{-1},
+		},
+		Dates:    []string{"2019-12-31T09:00:00Z", "2020-12-31T09:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly week 1 in 1900",
+		String: "FREQ=YEARLY;COUNT=3;BYDAY=MO;BYWEEKNO=1",
+		RRule: RRule{
+			Frequency:     Yearly,
+			Count:         3,
+			Dtstart:       time.Date(1899, time.June, 1, 12, 0, 0, 0, time.UTC),
+			ByWeekNumbers: []int{1},
+			ByWeekdays:    []QualifiedWeekday{{WD: time.Monday}},
+		},
+		Dates:    []string{"1900-01-01T12:00:00Z", "1900-12-31T12:00:00Z", "1901-12-30T12:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "daily over february 1900",
+		String: "FREQ=DAILY;COUNT=3",
+		RRule: RRule{
+			Frequency: Daily,
+			Count:     3,
+			Dtstart:   time.Date(1900, time.February, 27, 12, 0, 0, 0, time.UTC),
+		},
+		Dates:    []string{"1900-02-27T12:00:00Z", "1900-02-28T12:00:00Z", "1900-03-01T12:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly week 53 in the 2200s",
+		String: "FREQ=YEARLY;COUNT=3;BYDAY=TH;BYWEEKNO=53",
+		RRule: RRule{
+			Frequency:     Yearly,
+			Count:         3,
+			Dtstart:       time.Date(2195, time.January, 1, 12, 0, 0, 0, time.UTC),
+			ByWeekNumbers: []int{53},
+			ByWeekdays:    []QualifiedWeekday{{WD: time.Thursday}},
+		},
+		Dates:    []string{"2195-12-31T12:00:00Z", "2201-12-31T12:00:00Z", "2207-12-31T12:00:00Z"},
+		Terminal: true,
+	},
+
+	{
+		Name:   "yearly by yearday across 2200",
+		String: "FREQ=YEARLY;COUNT=3;BYYEARDAY=60",
+		RRule: RRule{
+			Frequency:  Yearly,
+			Count:      3,
+			Dtstart:    time.Date(2199, time.January, 1, 12, 0, 0, 0, time.UTC),
+			ByYearDays: []int{60},
+		},
+		Dates:    []string{"2199-03-01T12:00:00Z", "2200-03-01T12:00:00Z", "2201-03-01T12:00:00Z"},
+		Terminal: true,
+	},
+
 	{
 		Name: "end of time",
 		RRule: RRule{
@@ -340,6 +1082,10 @@ var cases = []struct {
 	},
 }
 
+func weekdayPtr(wd time.Weekday) *time.Weekday {
+	return &wd
+}
+
 func MustRRule(str string) RRule {
 	r, err := ParseRRule(str)
 	if err != nil {
@@ -388,6 +1134,8 @@ func TestRRule(t *testing.T) {
 				dtstart := tc.RRule.Dtstart
 				tc.RRule.Dtstart = time.Time{}
 				assert.Equal(t, tc.String, tc.RRule.String(), "RRule does not render to the correct string")
+				assert.Equal(t, tc.String, parsed.Raw)
+				parsed.Raw = ""
 				assert.Equal(t, tc.RRule, parsed)
 
 				tc.RRule.Dtstart = dtstart.Truncate(time.Second) // restore dtstart, but truncate it because rrule only operates at second.
@@ -395,10 +1143,371 @@ func TestRRule(t *testing.T) {
 
 			dates := All(tc.RRule.Iterator(), 0)
 			assert.Equal(t, tc.Dates, rfcAll(dates))
+			assertChronological(t, tc.RRule, dates)
+		})
+	}
+}
+
+func TestRRuleStringRoundTrip(t *testing.T) {
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			rrule := tc.RRule
+			rrule.Dtstart = time.Time{}
+
+			parsed, err := ParseRRule(rrule.String())
+			require.NoError(t, err)
+
+			// UNTIL is written in UTC, so compare it as an instant
+			assert.True(t, rrule.Until.Equal(parsed.Until), "%v != %v", rrule.Until, parsed.Until)
+			rrule.Until, parsed.Until = time.Time{}, time.Time{}
+			parsed.Raw = ""
+
+			assert.Equal(t, rrule, parsed)
+		})
+	}
+
+	zoned := RRule{Frequency: Daily, Until: time.Date(2018, 8, 25, 9, 0, 0, 0, NewYork())}
+	assert.Equal(t, "FREQ=DAILY;UNTIL=20180825T130000Z", zoned.String())
+}
+
+func TestIteratorStreaming(t *testing.T) {
+	start := now.Truncate(time.Second)
+
+	// an infinite pattern yields instances one period at a time
+	it := RRule{Frequency: Secondly, ByMinutes: []int{0, 30}, Dtstart: start}.Iterator()
+	var first []time.Time
+	for i := 0; i < 3; i++ {
+		first = append(first, *it.Next())
+	}
+	assert.Equal(t, []string{"2018-08-25T09:30:00Z", "2018-08-25T09:30:01Z", "2018-08-25T09:30:02Z"}, rfcAll(first))
+
+	// once COUNT or UNTIL is exhausted, the iterator keeps returning nil
+	for _, rrule := range []RRule{
+		{Frequency: Daily, Count: 2, Dtstart: start},
+		{Frequency: Daily, Until: start.AddDate(0, 0, 1), Dtstart: start},
+	} {
+		it := rrule.Iterator()
+		assert.NotNil(t, it.Next())
+		assert.NotNil(t, it.Next())
+		assert.Nil(t, it.Peek())
+		assert.Nil(t, it.Next())
+		assert.Nil(t, it.Next())
+	}
+}
+
+func TestIsSimple(t *testing.T) {
+	assert.True(t, MustRRule("FREQ=DAILY;INTERVAL=2;COUNT=3").IsSimple())
+	assert.True(t, MustRRule("FREQ=WEEKLY;UNTIL=20180830T000000Z;WKST=SU").IsSimple())
+	assert.False(t, MustRRule("FREQ=WEEKLY;BYDAY=TU").IsSimple())
+	assert.False(t, MustRRule("FREQ=YEARLY;BYYEARDAY=100").IsSimple())
+}
+
+func TestIsInfinite(t *testing.T) {
+	assert.True(t, MustRRule("FREQ=DAILY").IsInfinite())
+	assert.True(t, MustRRule("FREQ=WEEKLY;BYDAY=TU").IsInfinite())
+	assert.False(t, MustRRule("FREQ=DAILY;COUNT=3").IsInfinite())
+	assert.False(t, MustRRule("FREQ=WEEKLY;UNTIL=20180830T000000Z").IsInfinite())
+}
+
+func TestRRuleInvalidBehavior(t *testing.T) {
+	leap := RRule{Frequency: Yearly, Count: 3, Dtstart: time.Date(2020, time.February, 29, 9, 0, 0, 0, time.UTC)}
+
+	leap.InvalidBehavior = OmitInvalid
+	assert.Equal(t, []string{"2020-02-29T09:00:00Z", "2024-02-29T09:00:00Z", "2028-02-29T09:00:00Z"}, rfcAll(All(leap.Iterator(), 0)))
+
+	leap.InvalidBehavior = PrevInvalid
+	assert.Equal(t, []string{"2020-02-29T09:00:00Z", "2021-02-28T09:00:00Z", "2022-02-28T09:00:00Z"}, rfcAll(All(leap.Iterator(), 0)))
+
+	leap.InvalidBehavior = NextInvalid
+	assert.Equal(t, []string{"2020-02-29T09:00:00Z", "2021-03-01T09:00:00Z", "2022-03-01T09:00:00Z"}, rfcAll(All(leap.Iterator(), 0)))
+
+	// the day of each instance is still taken from Dtstart after a short month
+	monthly := RRule{Frequency: Monthly, Count: 3, Dtstart: time.Date(2021, time.January, 31, 9, 0, 0, 0, time.UTC), InvalidBehavior: PrevInvalid}
+	assert.Equal(t, []string{"2021-01-31T09:00:00Z", "2021-02-28T09:00:00Z", "2021-03-31T09:00:00Z"}, rfcAll(All(monthly.Iterator(), 0)))
+
+	// BYMONTHDAY=31 skips February and April by default
+	monthday := MustRRule("FREQ=MONTHLY;COUNT=4;BYMONTHDAY=31")
+	monthday.Dtstart = time.Date(2021, time.January, 1, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, OmitInvalid, monthday.InvalidBehavior)
+	assert.Equal(t, []string{"2021-01-31T09:00:00Z", "2021-03-31T09:00:00Z", "2021-05-31T09:00:00Z", "2021-07-31T09:00:00Z"}, rfcAll(monthday.All(0)))
+
+	monthday.InvalidBehavior = PrevInvalid
+	assert.Equal(t, []string{"2021-01-31T09:00:00Z", "2021-02-28T09:00:00Z", "2021-03-31T09:00:00Z", "2021-04-30T09:00:00Z"}, rfcAll(monthday.All(0)))
+
+	monthday.InvalidBehavior = NextInvalid
+	assert.Equal(t, []string{"2021-01-31T09:00:00Z", "2021-03-01T09:00:00Z", "2021-03-31T09:00:00Z", "2021-05-01T09:00:00Z"}, rfcAll(monthday.All(0)))
+
+	// yearly instances on a day that some months of BYMONTH lack
+	yearly := MustRRule("FREQ=YEARLY;COUNT=2;BYMONTH=2,4")
+	yearly.Dtstart = time.Date(2022, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+	yearly.InvalidBehavior = PrevInvalid
+	assert.Equal(t, []string{"2022-02-28T09:00:00Z", "2022-04-30T09:00:00Z"}, rfcAll(yearly.All(0)))
+
+	yearly.InvalidBehavior = NextInvalid
+	assert.Equal(t, []string{"2022-03-01T09:00:00Z", "2022-05-01T09:00:00Z"}, rfcAll(yearly.All(0)))
+
+	// the last day counted back from the end also falls outside short months
+	monthday = MustRRule("FREQ=MONTHLY;COUNT=2;BYMONTHDAY=-31")
+	monthday.Dtstart = time.Date(2021, time.February, 1, 9, 0, 0, 0, time.UTC)
+	monthday.InvalidBehavior = PrevInvalid
+	assert.Equal(t, []string{"2021-03-01T09:00:00Z", "2021-03-31T09:00:00Z"}, rfcAll(monthday.All(0)))
+}
+
+func TestRRuleYearlyNegativeMonthDay(t *testing.T) {
+	// -1 is the last day of February, not of the month of Dtstart
+	rrule := MustRRule("FREQ=YEARLY;COUNT=3;BYMONTH=2;BYMONTHDAY=-1")
+	rrule.Dtstart = time.Date(2019, time.January, 15, 9, 0, 0, 0, time.UTC)
+
+	for _, ib := range []InvalidBehavior{OmitInvalid, PrevInvalid} {
+		rrule.InvalidBehavior = ib
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		all, err := rrule.AllContext(ctx, 0)
+		cancel()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2019-02-28T09:00:00Z", "2020-02-29T09:00:00Z", "2021-02-28T09:00:00Z"}, rfcAll(all))
+	}
+}
+
+func TestRRuleGapBehavior(t *testing.T) {
+	// New York skips from 2:00 to 3:00 on 2018-03-11
+	daily := RRule{Frequency: Daily, Count: 4, Dtstart: time.Date(2018, time.March, 9, 2, 30, 0, 0, NewYork())}
+	assert.Equal(t, []string{"2018-03-09T02:30:00-05:00", "2018-03-10T02:30:00-05:00", "2018-03-11T03:30:00-04:00", "2018-03-12T02:30:00-04:00"}, rfcAll(All(daily.Iterator(), 0)))
+
+	daily.GapBehavior = SkipGap
+	assert.Equal(t, []string{"2018-03-09T02:30:00-05:00", "2018-03-10T02:30:00-05:00", "2018-03-12T02:30:00-04:00", "2018-03-13T02:30:00-04:00"}, rfcAll(All(daily.Iterator(), 0)))
+
+	weekly := RRule{Frequency: Weekly, Count: 3, Dtstart: time.Date(2018, time.March, 4, 2, 30, 0, 0, NewYork())}
+	assert.Equal(t, []string{"2018-03-04T02:30:00-05:00", "2018-03-11T03:30:00-04:00", "2018-03-18T02:30:00-04:00"}, rfcAll(All(weekly.Iterator(), 0)))
+
+	weekly.GapBehavior = SkipGap
+	assert.Equal(t, []string{"2018-03-04T02:30:00-05:00", "2018-03-18T02:30:00-04:00", "2018-03-25T02:30:00-04:00"}, rfcAll(All(weekly.Iterator(), 0)))
+
+	// BYHOUR sets the wall clock, not the time elapsed since midnight
+	hours := MustRRule("FREQ=DAILY;COUNT=4;BYHOUR=2,9")
+	hours.Dtstart = time.Date(2018, time.March, 10, 0, 0, 0, 0, NewYork())
+	assert.Equal(t, []string{"2018-03-10T02:00:00-05:00", "2018-03-10T09:00:00-05:00", "2018-03-11T03:00:00-04:00", "2018-03-11T09:00:00-04:00"}, rfcAll(All(hours.Iterator(), 0)))
+
+	hours.GapBehavior = SkipGap
+	assert.Equal(t, []string{"2018-03-10T02:00:00-05:00", "2018-03-10T09:00:00-05:00", "2018-03-11T09:00:00-04:00", "2018-03-12T02:00:00-04:00"}, rfcAll(All(hours.Iterator(), 0)))
+}
+
+func TestRRuleAllContext(t *testing.T) {
+	rrule := MustRRule("FREQ=DAILY;COUNT=3")
+	rrule.Dtstart = now
+
+	all, err := rrule.AllContext(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, rrule.All(0), all)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	all, err = rrule.AllContext(ctx, 0)
+	assert.Equal(t, context.Canceled, err)
+	assert.Empty(t, all)
+
+	// February 30th never happens, so only the deadline ends the search
+	never := MustRRule("FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30")
+	never.Dtstart = now
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	it := never.IteratorContext(ctx)
+	assert.Nil(t, it.Next())
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+}
+
+func TestRRuleDuplicates(t *testing.T) {
+	cases := []struct {
+		RRule string
+		Want  []string
+	}{
+		{"FREQ=YEARLY;COUNT=3;BYMONTH=1;BYMONTHDAY=1;BYYEARDAY=1", []string{"2019-01-01T00:00:00Z", "2020-01-01T00:00:00Z", "2021-01-01T00:00:00Z"}},
+		{"FREQ=MONTHLY;COUNT=3;BYMONTHDAY=28,-1", []string{"2019-01-28T00:00:00Z", "2019-01-31T00:00:00Z", "2019-02-28T00:00:00Z"}},
+		{"FREQ=MONTHLY;COUNT=3;BYDAY=1MO,MO", []string{"2019-01-07T00:00:00Z", "2019-01-14T00:00:00Z", "2019-01-21T00:00:00Z"}},
+		{"FREQ=YEARLY;COUNT=3;BYWEEKNO=1,-52;BYDAY=MO", []string{"2019-12-30T00:00:00Z", "2020-01-06T00:00:00Z", "2021-01-04T00:00:00Z"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.RRule, func(t *testing.T) {
+			rrule := MustRRule(tc.RRule)
+			rrule.Dtstart = time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+			assert.Equal(t, tc.Want, rfcAll(rrule.All(0)))
+		})
+	}
+
+	// 2:30 rolls forward to the 3:30 that's already an instance
+	gap := MustRRule("FREQ=DAILY;COUNT=3;BYHOUR=2,3;BYMINUTE=30")
+	gap.Dtstart = time.Date(2018, time.March, 11, 0, 0, 0, 0, NewYork())
+	assert.Equal(t, []string{"2018-03-11T03:30:00-04:00", "2018-03-12T02:30:00-04:00", "2018-03-12T03:30:00-04:00"}, rfcAll(gap.All(0)))
+}
+
+func TestRRuleSorted(t *testing.T) {
+	rrule := MustRRule("FREQ=DAILY;COUNT=6;BYHOUR=10,9;BYMINUTE=30,0,15")
+	rrule.Dtstart = time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2019-01-01T09:00:00Z", "2019-01-01T09:15:00Z", "2019-01-01T09:30:00Z", "2019-01-01T10:00:00Z", "2019-01-01T10:15:00Z", "2019-01-01T10:30:00Z"}, rfcAll(rrule.All(0)))
+
+	// BYSETPOS counts through the instances in order
+	rrule.BySetPos = []int{1, -1}
+	rrule.Count = 2
+	assert.Equal(t, []string{"2019-01-01T09:00:00Z", "2019-01-01T10:30:00Z"}, rfcAll(rrule.All(0)))
+}
+
+func TestRRuleWeekStartDefault(t *testing.T) {
+	monday, sunday := time.Monday, time.Sunday
+	start := time.Date(2018, 8, 26, 9, 0, 0, 0, time.UTC) // a sunday
+
+	for _, str := range []string{
+		"FREQ=WEEKLY;COUNT=6;INTERVAL=2;BYDAY=SU,MO,SA",
+		"FREQ=YEARLY;COUNT=6;BYWEEKNO=1,-1;BYDAY=SU",
+	} {
+		t.Run(str, func(t *testing.T) {
+			rrule := MustRRule(str)
+			rrule.Dtstart = start
+			defaulted := rfcAll(rrule.All(0))
+
+			rrule.WeekStart = &monday
+			assert.Equal(t, defaulted, rfcAll(rrule.All(0)))
+
+			rrule.WeekStart = &sunday
+			assert.NotEqual(t, defaulted, rfcAll(rrule.All(0)))
 		})
 	}
 }
 
+func TestRRuleClone(t *testing.T) {
+	template := MustRRule("FREQ=MONTHLY;BYDAY=MO,FR;BYMONTHDAY=1,2,3;BYMONTH=1;BYSETPOS=1;WKST=SU")
+	clone := template.Clone()
+	assert.Equal(t, template, clone)
+
+	clone.ByWeekdays[0].WD = time.Tuesday
+	clone.ByMonthDays[0] = 4
+	clone.ByMonths[0] = time.February
+	clone.BySetPos[0] = -1
+	*clone.WeekStart = time.Monday
+
+	assert.Equal(t, "FREQ=MONTHLY;BYDAY=MO,FR;BYMONTHDAY=1,2,3;BYMONTH=1;BYSETPOS=1;WKST=SU", template.String())
+	assert.Nil(t, RRule{}.Clone().ByHours)
+}
+
+func TestValidateRanges(t *testing.T) {
+	invalid := map[string]string{
+		"FREQ=YEARLY;BYMONTH=13":      "BYMONTH value 13 must be between [1,12]",
+		"FREQ=YEARLY;BYMONTH=0":       "BYMONTH value 0 must be between [1,12]",
+		"FREQ=DAILY;BYHOUR=25":        "BYHOUR value 25 must be between [-24,23]",
+		"FREQ=DAILY;BYMINUTE=9,60":    "BYMINUTE value 60 must be between [-60,59]",
+		"FREQ=DAILY;BYSECOND=61":      "BYSECOND value 61 must be between [-60,60]",
+		"FREQ=MONTHLY;BYMONTHDAY=0":   "BYMONTHDAY value 0 must be between [-31,-1] or [1,31]",
+		"FREQ=MONTHLY;BYMONTHDAY=-32": "BYMONTHDAY value -32 must be between [-31,-1] or [1,31]",
+		"FREQ=YEARLY;BYYEARDAY=367":   "BYYEARDAY value 367 must be between [-366,-1] or [1,366]",
+		"FREQ=YEARLY;BYWEEKNO=54":     "BYWEEKNO value 54 must be between [-53,-1] or [1,53]",
+		"FREQ=YEARLY;BYDAY=54MO":      "BYDAY value 54MO must have a numeric component between [-53,-1] or [1,53]",
+	}
+	for str, msg := range invalid {
+		rrule, err := ParseRRule(str)
+		assert.EqualError(t, err, msg, str)
+		assert.EqualError(t, rrule.Validate(), msg, str)
+	}
+
+	for _, str := range []string{
+		"FREQ=DAILY;BYSECOND=0,60,-60;BYMINUTE=0,59,-60;BYHOUR=0,23,-24",
+		"FREQ=YEARLY;BYMONTHDAY=1,31,-31;BYYEARDAY=1,366,-366;BYWEEKNO=1,53,-53;BYMONTH=1,12",
+		"FREQ=YEARLY;BYDAY=53MO,-53FR",
+	} {
+		_, err := ParseRRule(str)
+		assert.NoError(t, err, str)
+	}
+}
+
+func TestValidateCountAndUntil(t *testing.T) {
+	_, err := ParseRRule("FREQ=DAILY;COUNT=3;UNTIL=20180901T000000Z")
+	assert.EqualError(t, err, "COUNT and UNTIL must not appear in the same RRULE")
+
+	rrule := RRule{Frequency: Daily, Count: 3, Until: time.Date(2018, 9, 1, 0, 0, 0, 0, time.UTC)}
+	assert.EqualError(t, rrule.Validate(), "COUNT and UNTIL must not appear in the same RRULE")
+
+	rrule.Count = 0
+	assert.NoError(t, rrule.Validate())
+}
+
+func TestValidateBySetPosAlone(t *testing.T) {
+	for _, str := range []string{"FREQ=MONTHLY;BYSETPOS=1", "FREQ=YEARLY;COUNT=2;BYSETPOS=-1;WKST=SU"} {
+		_, err := ParseRRule(str)
+		assert.EqualError(t, err, "BYSETPOS rules must be used in conjunction with at least one other BYXXX rule part", str)
+	}
+
+	_, err := ParseRRule("FREQ=MONTHLY;BYDAY=MO;BYSETPOS=1")
+	assert.NoError(t, err)
+}
+
+func TestValidateUntilBeforeDtstart(t *testing.T) {
+	start := time.Date(2018, 8, 25, 9, 0, 0, 0, NewYork())
+
+	rrule := RRule{Frequency: Daily, Dtstart: start, Until: start.Add(-time.Hour)}
+	assert.Error(t, rrule.Validate())
+	assert.Empty(t, All(rrule.Iterator(), 0))
+
+	// 12:00 UTC is 08:00 in New York, before the start
+	rrule.Until = time.Date(2018, 8, 25, 12, 0, 0, 0, time.UTC)
+	assert.Error(t, rrule.Validate())
+
+	// but a floating 12:00 is 12:00 in New York, after the start
+	rrule.UntilFloating = true
+	assert.NoError(t, rrule.Validate())
+
+	rrule = RRule{Frequency: Daily, Dtstart: start, Until: start}
+	assert.NoError(t, rrule.Validate())
+
+	rrule = RRule{Frequency: Daily, Until: start.AddDate(-1, 0, 0)}
+	assert.NoError(t, rrule.Validate(), "without Dtstart, Until can't be checked")
+}
+
+func TestRRuleAllHorizon(t *testing.T) {
+	start := time.Date(2099, 12, 29, 0, 0, 0, 0, time.UTC)
+
+	daily := RRule{Frequency: Daily, Dtstart: start}
+	assert.Equal(t, []string{"2099-12-29T00:00:00Z", "2099-12-30T00:00:00Z", "2099-12-31T00:00:00Z", "2100-01-01T00:00:00Z"}, rfcAll(daily.All(0)))
+
+	// a limit, COUNT, or UNTIL takes precedence over the horizon
+	assert.Len(t, daily.All(6), 6)
+	assert.Len(t, daily.WithCount(6).All(0), 6)
+
+	defer func(horizon time.Time) { DefaultHorizon = horizon }(DefaultHorizon)
+	DefaultHorizon = start.AddDate(0, 0, 1)
+	assert.Equal(t, []string{"2099-12-29T00:00:00Z", "2099-12-30T00:00:00Z"}, rfcAll(daily.All(0)))
+}
+
+func TestRRuleAllLimit(t *testing.T) {
+	secondly := RRule{Frequency: Secondly, Dtstart: now}
+	assert.Len(t, secondly.All(0), DefaultLimit)
+
+	defer func(limit int) { DefaultLimit = limit }(DefaultLimit)
+	DefaultLimit = 10
+	assert.Len(t, secondly.All(0), 10)
+	assert.Len(t, secondly.All(20), 20, "an explicit limit takes precedence")
+	assert.Len(t, secondly.WithCount(20).All(0), 20, "finite patterns aren't limited")
+	assert.Len(t, Recurrence{Dtstart: now, RRules: []RRule{secondly}}.All(0), 10)
+}
+
+// assertChronological checks the invariants every generated sequence must
+// hold, regardless of the pattern: instances are strictly increasing, and
+// fall within [Dtstart, Until].
+func assertChronological(t *testing.T, rrule RRule, dates []time.Time) {
+	t.Helper()
+
+	for i, d := range dates {
+		if i > 0 && !d.After(dates[i-1]) {
+			t.Errorf("instance %d (%v) does not follow instance %d (%v)", i, d, i-1, dates[i-1])
+		}
+		if !rrule.Dtstart.IsZero() && d.Before(rrule.Dtstart) {
+			t.Errorf("instance %d (%v) is before Dtstart (%v)", i, d, rrule.Dtstart)
+		}
+		if !rrule.Until.IsZero() && d.After(rrule.lastUntil()) {
+			t.Errorf("instance %d (%v) is after Until (%v)", i, d, rrule.Until)
+		}
+	}
+}
+
 // TestAgainstTeambition checks that our test case expectations match against
 // an existing RRULE library.
 func TestAgainstTeambition(t *testing.T) {
@@ -477,27 +1586,35 @@ func rruleToROption(r RRule) rrule.ROption {
 		converted.Bymonth = append(converted.Bymonth, int(m))
 	}
 	for _, wd := range r.ByWeekdays {
-		switch wd.WD {
-		case time.Sunday:
-			converted.Byweekday = append(converted.Byweekday, rrule.SU.Nth(wd.N))
-		case time.Monday:
-			converted.Byweekday = append(converted.Byweekday, rrule.MO.Nth(wd.N))
-		case time.Tuesday:
-			converted.Byweekday = append(converted.Byweekday, rrule.TU.Nth(wd.N))
-		case time.Wednesday:
-			converted.Byweekday = append(converted.Byweekday, rrule.WE.Nth(wd.N))
-		case time.Thursday:
-			converted.Byweekday = append(converted.Byweekday, rrule.TH.Nth(wd.N))
-		case time.Friday:
-			converted.Byweekday = append(converted.Byweekday, rrule.FR.Nth(wd.N))
-		case time.Saturday:
-			converted.Byweekday = append(converted.Byweekday, rrule.SA.Nth(wd.N))
-		}
+		tw := teambitionWeekday(wd.WD)
+		converted.Byweekday = append(converted.Byweekday, tw.Nth(wd.N))
+	}
+	if r.WeekStart != nil {
+		converted.Wkst = teambitionWeekday(*r.WeekStart)
 	}
 
 	return converted
 }
 
+func teambitionWeekday(wd time.Weekday) rrule.Weekday {
+	switch wd {
+	case time.Sunday:
+		return rrule.SU
+	case time.Monday:
+		return rrule.MO
+	case time.Tuesday:
+		return rrule.TU
+	case time.Wednesday:
+		return rrule.WE
+	case time.Thursday:
+		return rrule.TH
+	case time.Friday:
+		return rrule.FR
+	default:
+		return rrule.SA
+	}
+}
+
 func BenchmarkTeambition(b *testing.B) {
 	for _, tc := range cases {
 
@@ -0,0 +1,134 @@
+package rrule
+
+import (
+	"container/heap"
+	"time"
+)
+
+// RecurrenceSet is a collection of recurrences, such as every event of a
+// calendar, whose instances are generated together in chronological order.
+type RecurrenceSet struct {
+	Recurrences []*Recurrence
+
+	// stream holds the state of Next
+	stream *setHeap
+}
+
+// SetOccurrence is a single instance of one of the recurrences of a
+// RecurrenceSet.
+type SetOccurrence struct {
+	Time       time.Time
+	Recurrence *Recurrence
+}
+
+// Between returns the instances of every recurrence in the set within
+// [start, end), sorted by time. Instances at the same time are ordered by the
+// position of their recurrence in Recurrences.
+//
+// Each recurrence is fast-forwarded to start where its patterns allow, then
+// iterated lazily and merged through a heap, so only the instances up to end
+// are generated.
+func (s *RecurrenceSet) Between(start, end time.Time) []SetOccurrence {
+	h := &setHeap{}
+	for i, r := range s.Recurrences {
+		it := r.iteratorFrom(start)
+		for {
+			next := it.Next()
+			if next == nil || !next.Before(end) {
+				break
+			}
+			if !next.Before(start) {
+				h.items = append(h.items, setHeapItem{next: *next, it: it, idx: i, r: r})
+				break
+			}
+		}
+	}
+	heap.Init(h)
+
+	var all []SetOccurrence
+	for h.Len() > 0 {
+		item := h.items[0]
+		all = append(all, SetOccurrence{Time: item.next, Recurrence: item.r})
+		h.advance(&end)
+	}
+
+	return all
+}
+
+// Next returns the next instance across every recurrence in the set, along
+// with the recurrence it belongs to, or false once every recurrence has
+// ended. Instances are returned in the same order as Between, but each
+// recurrence is only advanced as its instances are returned, so infinite
+// recurrences can be streamed.
+//
+// The first call to Next starts from the beginning of each of the set's
+// Recurrences; later changes to Recurrences are not seen by Next.
+func (s *RecurrenceSet) Next() (time.Time, *Recurrence, bool) {
+	if s.stream == nil {
+		s.stream = &setHeap{}
+		for i, r := range s.Recurrences {
+			it := r.Iterator()
+			if next := it.Next(); next != nil {
+				s.stream.items = append(s.stream.items, setHeapItem{next: *next, it: it, idx: i, r: r})
+			}
+		}
+		heap.Init(s.stream)
+	}
+
+	if s.stream.Len() == 0 {
+		return time.Time{}, nil, false
+	}
+
+	item := s.stream.items[0]
+	s.stream.advance(nil)
+	return item.next, item.r, true
+}
+
+// setHeapItem is the upcoming instance of one recurrence of a set.
+type setHeapItem struct {
+	next time.Time
+	it   Iterator
+	idx  int
+	r    *Recurrence
+}
+
+// setHeap is a min-heap of the upcoming instances of the recurrences of a
+// set, implementing heap.Interface.
+type setHeap struct {
+	items []setHeapItem
+}
+
+// advance replaces the earliest item with the following instance of its
+// recurrence, removing it if the recurrence has ended or, when end is not nil,
+// reached end.
+func (h *setHeap) advance(end *time.Time) {
+	item := &h.items[0]
+
+	next := item.it.Next()
+	if next == nil || (end != nil && !next.Before(*end)) {
+		heap.Pop(h)
+		return
+	}
+
+	item.next = *next
+	heap.Fix(h, 0)
+}
+
+func (h *setHeap) Len() int { return len(h.items) }
+
+func (h *setHeap) Less(i, j int) bool {
+	if h.items[i].next.Equal(h.items[j].next) {
+		return h.items[i].idx < h.items[j].idx
+	}
+	return h.items[i].next.Before(h.items[j].next)
+}
+
+func (h *setHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *setHeap) Push(x interface{}) { h.items = append(h.items, x.(setHeapItem)) }
+
+func (h *setHeap) Pop() interface{} {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
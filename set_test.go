@@ -0,0 +1,141 @@
+package rrule
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecurrenceSetBetween(t *testing.T) {
+	start := now.Truncate(time.Second) // a saturday
+
+	daily := &Recurrence{Dtstart: start, RRules: []RRule{{Frequency: Daily}}}
+	weekly := &Recurrence{Dtstart: start, RRules: []RRule{{Frequency: Weekly, ByWeekdays: []QualifiedWeekday{{WD: time.Monday}}}}}
+	once := &Recurrence{Dtstart: start, RDates: []time.Time{start.Add(36 * time.Hour)}}
+	ended := &Recurrence{Dtstart: start, RRules: []RRule{{Frequency: Daily, Count: 1}}}
+
+	set := &RecurrenceSet{Recurrences: []*Recurrence{daily, weekly, once, ended}}
+	occurrences := set.Between(start.Add(time.Hour), start.AddDate(0, 0, 3))
+
+	type tagged struct {
+		Time       string
+		Recurrence *Recurrence
+	}
+	var got []tagged
+	for _, o := range occurrences {
+		got = append(got, tagged{o.Time.Format(time.RFC3339), o.Recurrence})
+	}
+
+	assert.Equal(t, []tagged{
+		{"2018-08-26T09:08:07Z", daily},
+		{"2018-08-26T21:08:07Z", once},
+		{"2018-08-27T09:08:07Z", daily},
+		{"2018-08-27T09:08:07Z", weekly},
+	}, got)
+
+	assert.Empty(t, set.Between(start.AddDate(0, 0, 3), start.AddDate(0, 0, 3)))
+	assert.Empty(t, (&RecurrenceSet{}).Between(start, start.AddDate(1, 0, 0)))
+
+	// a window long after DTSTART, with an EXRULE
+	minutely := &Recurrence{
+		Dtstart: start,
+		RRules:  []RRule{{Frequency: Minutely, Interval: 10}},
+		ExRules: []RRule{{Frequency: Minutely, Interval: 20}},
+	}
+	from := start.AddDate(2, 0, 0)
+	var times []string
+	for _, o := range (&RecurrenceSet{Recurrences: []*Recurrence{minutely}}).Between(from, from.Add(time.Hour)) {
+		times = append(times, o.Time.Format(time.RFC3339))
+	}
+	assert.Equal(t, []string{"2020-08-25T09:18:07Z", "2020-08-25T09:38:07Z", "2020-08-25T09:58:07Z"}, times)
+}
+
+func BenchmarkRecurrenceSetBetween(b *testing.B) {
+	start := now.Truncate(time.Second)
+	set := benchmarkSet()
+
+	from := start.AddDate(0, 1, 0)
+	to := from.AddDate(0, 1, 0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Between(from, to)
+	}
+}
+
+func TestRecurrenceSetNext(t *testing.T) {
+	start := now.Truncate(time.Second)
+
+	hourly := &Recurrence{Dtstart: start, RRules: []RRule{{Frequency: Hourly, Interval: 5}}}
+	daily := &Recurrence{Dtstart: start.Add(time.Hour), RRules: []RRule{{Frequency: Daily, Count: 2}}}
+
+	set := &RecurrenceSet{Recurrences: []*Recurrence{hourly, daily}}
+
+	var got []string
+	var sources []*Recurrence
+	for i := 0; i < 8; i++ {
+		next, r, ok := set.Next()
+		if !assert.True(t, ok) {
+			break
+		}
+		got = append(got, next.Format(time.RFC3339))
+		sources = append(sources, r)
+	}
+
+	assert.Equal(t, []string{
+		"2018-08-25T09:08:07Z", "2018-08-25T10:08:07Z", "2018-08-25T14:08:07Z", "2018-08-25T19:08:07Z",
+		"2018-08-26T00:08:07Z", "2018-08-26T05:08:07Z", "2018-08-26T10:08:07Z", "2018-08-26T10:08:07Z",
+	}, got)
+	assert.Equal(t, []*Recurrence{hourly, daily, hourly, hourly, hourly, hourly, hourly, daily}, sources)
+
+	finite := &RecurrenceSet{Recurrences: []*Recurrence{daily}}
+	for i := 0; i < 2; i++ {
+		_, _, ok := finite.Next()
+		assert.True(t, ok)
+	}
+	_, r, ok := finite.Next()
+	assert.False(t, ok)
+	assert.Nil(t, r)
+}
+
+func benchmarkSet() *RecurrenceSet {
+	start := now.Truncate(time.Second)
+
+	set := &RecurrenceSet{}
+	for i := 0; i < 500; i++ {
+		set.Recurrences = append(set.Recurrences, &Recurrence{
+			Dtstart: start.Add(time.Duration(i) * time.Minute),
+			RRules:  []RRule{{Frequency: Daily, Interval: 1 + i%7}},
+		})
+	}
+	return set
+}
+
+func BenchmarkRecurrenceSetNext(b *testing.B) {
+	set := benchmarkSet()
+
+	for i := 0; i < b.N; i++ {
+		set.stream = nil
+		for j := 0; j < 1000; j++ {
+			set.Next()
+		}
+	}
+}
+
+// BenchmarkRecurrenceSetMergeSorted is the naive alternative to
+// BenchmarkRecurrenceSetNext: materialize enough of every recurrence to be
+// sure of the first 1000 instances, then sort them all.
+func BenchmarkRecurrenceSetMergeSorted(b *testing.B) {
+	set := benchmarkSet()
+
+	for i := 0; i < b.N; i++ {
+		var all []time.Time
+		for _, r := range set.Recurrences {
+			all = append(all, All(r.Iterator(), 1000)...)
+		}
+		sort.Slice(all, func(i, j int) bool { return all[i].Before(all[j]) })
+		_ = all[:1000]
+	}
+}
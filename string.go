@@ -1,12 +1,15 @@
 package rrule
 
 import (
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// String returns the RFC 5545 representation of the RRule.
+// String returns the RFC 5545 representation of the RRule, which ParseRRule
+// parses back into an equal RRule, apart from Dtstart, which isn't part of
+// the representation, and Until, which is written in UTC.
 func (rrule RRule) String() string {
 	str := &strings.Builder{}
 	str.WriteString("FREQ=")
@@ -14,10 +17,13 @@ func (rrule RRule) String() string {
 
 	if !rrule.Until.IsZero() {
 		str.WriteString(";UNTIL=")
-		if rrule.UntilFloating {
+		if rrule.UntilDate {
+			str.WriteString(rrule.Until.Format(rfc5545Date))
+		} else if rrule.UntilFloating {
 			str.WriteString(rrule.Until.Format(rfc5545WithoutOffset))
 		} else {
-			str.WriteString(rrule.Until.Format(rfc5545WithOffset))
+			// RFC 5545 requires a UTC UNTIL unless it's floating
+			str.WriteString(rrule.Until.UTC().Format(rfc5545WithOffset))
 		}
 	}
 
@@ -61,6 +67,11 @@ func (rrule RRule) String() string {
 		str.WriteString(intlist(rrule.ByYearDays))
 	}
 
+	if len(rrule.ByWeekNumbers) > 0 {
+		str.WriteString(";BYWEEKNO=")
+		str.WriteString(intlist(rrule.ByWeekNumbers))
+	}
+
 	if len(rrule.ByMonths) > 0 {
 		str.WriteString(";BYMONTH=")
 		str.WriteString(monthlist(rrule.ByMonths))
@@ -79,6 +90,125 @@ func (rrule RRule) String() string {
 	return str.String()
 }
 
+// MarshalText implements encoding.TextMarshaler, so an RRule is encoded by
+// packages such as encoding/json as its String. Like String, it doesn't
+// include Dtstart.
+func (rrule RRule) MarshalText() ([]byte, error) {
+	return []byte(rrule.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler by parsing text with
+// ParseRRule. Dtstart is left as it was.
+func (rrule *RRule) UnmarshalText(text []byte) error {
+	parsed, err := ParseRRule(string(text))
+	if err != nil {
+		return err
+	}
+
+	parsed.Dtstart = rrule.Dtstart
+	*rrule = parsed
+	return nil
+}
+
+// CompactString returns a minimal RFC 5545 representation of the RRule,
+// suitable for storage or comparison. Unlike String, parts equal to their
+// default, such as INTERVAL=1 or WKST=MO, are omitted, and the values of each
+// BYxxx part are sorted and deduplicated. BYDAY is sorted by N, then by
+// position in the week starting at WKST.
+func (rrule RRule) CompactString() string {
+	return rrule.compact().String()
+}
+
+// Equal reports whether rrule and other are the same pattern. Comparison is
+// normalization-aware, like CompactString: the order of, and duplicates in,
+// BYxxx parts are ignored, as are defaults, so a nil WeekStart equals Monday
+// and an Interval of 0 equals 1. Dtstart and Until are compared as instants,
+// and Raw is ignored.
+func (rrule RRule) Equal(other RRule) bool {
+	return rrule.Dtstart.Equal(other.Dtstart) &&
+		rrule.InvalidBehavior == other.InvalidBehavior &&
+		rrule.GapBehavior == other.GapBehavior &&
+		rrule.CompactString() == other.CompactString()
+}
+
+// compact returns a copy of rrule with defaults omitted and BYxxx parts
+// sorted and deduplicated. The slices of the copy are newly allocated.
+func (rrule RRule) compact() RRule {
+	weekStart := rrule.weekStart()
+
+	if rrule.Interval == 1 {
+		rrule.Interval = 0
+	}
+	if rrule.WeekStart != nil && *rrule.WeekStart == DefaultWeekStart {
+		rrule.WeekStart = nil
+	}
+
+	rrule.BySeconds = sortedInts(rrule.BySeconds)
+	rrule.ByMinutes = sortedInts(rrule.ByMinutes)
+	rrule.ByHours = sortedInts(rrule.ByHours)
+	rrule.ByMonthDays = sortedInts(rrule.ByMonthDays)
+	rrule.ByYearDays = sortedInts(rrule.ByYearDays)
+	rrule.ByWeekNumbers = sortedInts(rrule.ByWeekNumbers)
+	rrule.BySetPos = sortedInts(rrule.BySetPos)
+
+	if len(rrule.ByMonths) > 0 {
+		months := make([]time.Month, 0, len(rrule.ByMonths))
+		seen := map[time.Month]bool{}
+		for _, m := range rrule.ByMonths {
+			if !seen[m] {
+				months = append(months, m)
+			}
+			seen[m] = true
+		}
+		sort.Slice(months, func(i, j int) bool { return months[i] < months[j] })
+		rrule.ByMonths = months
+	} else {
+		rrule.ByMonths = nil
+	}
+
+	if len(rrule.ByWeekdays) > 0 {
+		wds := make([]QualifiedWeekday, 0, len(rrule.ByWeekdays))
+		seen := map[QualifiedWeekday]bool{}
+		for _, wd := range rrule.ByWeekdays {
+			if !seen[wd] {
+				wds = append(wds, wd)
+			}
+			seen[wd] = true
+		}
+		// weekdays are ordered as they fall in the week beginning at WKST
+		sort.Slice(wds, func(i, j int) bool {
+			if wds[i].N != wds[j].N {
+				return wds[i].N < wds[j].N
+			}
+			return daysFrom(wds[i].WD, weekStart) < daysFrom(wds[j].WD, weekStart)
+		})
+		rrule.ByWeekdays = wds
+	} else {
+		rrule.ByWeekdays = nil
+	}
+
+	return rrule
+}
+
+// sortedInts returns a sorted copy of ints with duplicates removed, or nil if
+// ints is empty.
+func sortedInts(ints []int) []int {
+	if len(ints) == 0 {
+		return nil
+	}
+
+	sorted := make([]int, 0, len(ints))
+	seen := make(map[int]bool, len(ints))
+	for _, n := range ints {
+		if !seen[n] {
+			sorted = append(sorted, n)
+		}
+		seen[n] = true
+	}
+	sort.Ints(sorted)
+	return sorted
+}
+
 func intlist(ints []int) string {
 	b := &strings.Builder{}
 	for i, n := range ints {
@@ -0,0 +1,93 @@
+package rrule
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactString(t *testing.T) {
+	cases := []struct {
+		Input   string
+		Compact string
+	}{
+		{"FREQ=DAILY;INTERVAL=1", "FREQ=DAILY"},
+		{"FREQ=WEEKLY;BYDAY=TU;WKST=MO", "FREQ=WEEKLY;BYDAY=TU"},
+		{"FREQ=WEEKLY;BYDAY=TU;WKST=SU", "FREQ=WEEKLY;BYDAY=TU;WKST=SU"},
+		{"FREQ=DAILY;BYHOUR=17,9,9;BYMINUTE=30,0", "FREQ=DAILY;BYMINUTE=0,30;BYHOUR=9,17"},
+		{"FREQ=YEARLY;BYMONTH=12,1;BYWEEKNO=20,-1", "FREQ=YEARLY;BYWEEKNO=-1,20;BYMONTH=1,12"},
+		{"FREQ=MONTHLY;INTERVAL=2;COUNT=3", "FREQ=MONTHLY;COUNT=3;INTERVAL=2"},
+		{"FREQ=WEEKLY;BYDAY=FR,MO,WE;WKST=MO", "FREQ=WEEKLY;BYDAY=MO,WE,FR"},
+		{"FREQ=WEEKLY;BYDAY=SA,MO,SU", "FREQ=WEEKLY;BYDAY=MO,SA,SU"},
+		{"FREQ=WEEKLY;BYDAY=SA,MO,SU;WKST=SU", "FREQ=WEEKLY;BYDAY=SU,MO,SA;WKST=SU"},
+		{"FREQ=MONTHLY;BYDAY=2TU,-1SU,1FR,1MO", "FREQ=MONTHLY;BYDAY=-1SU,1MO,1FR,2TU"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Input, func(t *testing.T) {
+			rrule, err := ParseRRule(tc.Input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.Compact, rrule.CompactString())
+		})
+	}
+}
+
+func TestRRuleText(t *testing.T) {
+	type config struct {
+		Schedule RRule
+		Optional *RRule
+	}
+
+	in := config{
+		Schedule: RRule{
+			Frequency:  Monthly,
+			Count:      5,
+			Interval:   2,
+			ByWeekdays: []QualifiedWeekday{{N: -1, WD: time.Friday}, {N: 1, WD: time.Monday}},
+			ByMonths:   []time.Month{time.January, time.June},
+			WeekStart:  weekdayPtr(time.Sunday),
+		},
+	}
+
+	b, err := json.Marshal(in)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Schedule":"FREQ=MONTHLY;COUNT=5;INTERVAL=2;BYDAY=-1FR,1MO;BYMONTH=1,6;WKST=SU","Optional":null}`, string(b))
+
+	var out config
+	require.NoError(t, json.Unmarshal(b, &out))
+	assert.Equal(t, in.Schedule.String(), out.Schedule.Raw)
+	out.Schedule.Raw = ""
+	assert.Equal(t, in, out)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"Schedule":"FREQ=NEVER"}`), &out))
+}
+
+func TestRRuleEqual(t *testing.T) {
+	dtstart := time.Date(2018, 8, 25, 9, 0, 0, 0, time.UTC)
+
+	a := MustRRule("FREQ=WEEKLY;INTERVAL=1;BYDAY=FR,MO;WKST=MO;UNTIL=20181231T000000Z")
+	a.Dtstart = dtstart
+	b := MustRRule("FREQ=WEEKLY;UNTIL=20181231T000000Z;BYDAY=MO,FR,MO")
+	b.Dtstart = dtstart.In(NewYork())
+
+	assert.True(t, a.Equal(b))
+	assert.True(t, b.Equal(a))
+
+	b.Until = b.Until.In(NewYork())
+	assert.True(t, a.Equal(b), "Until is compared as an instant")
+
+	c := b
+	c.WeekStart = weekdayPtr(time.Sunday)
+	assert.False(t, a.Equal(c))
+
+	c = b
+	c.Dtstart = dtstart.Add(time.Second)
+	assert.False(t, a.Equal(c))
+
+	c = b
+	c.ByWeekdays = []QualifiedWeekday{{WD: time.Monday}, {N: 1, WD: time.Friday}}
+	assert.False(t, a.Equal(c))
+}
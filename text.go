@@ -0,0 +1,231 @@
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Text returns a plain English description of the pattern suitable for
+// showing to users, such as "every 2 weeks on Tuesday and Thursday until Dec
+// 31, 2018". Unlike Describe, it returns an error rather than an awkward or
+// misleading description when the pattern uses parts it can't describe:
+// BYSETPOS, BYYEARDAY, BYWEEKNO, BYHOUR, BYMINUTE, BYSECOND, and a WKST that
+// changes the instances.
+func (rrule RRule) Text() (string, error) {
+	return rrule.text(english)
+}
+
+// phrasebook holds the words and grammar a description is built from, so
+// that Text can be extended to languages other than English.
+type phrasebook struct {
+	every          string
+	units          map[Frequency][2]string // singular and plural
+	weekdayUnit    string                  // every weekday
+	weekendDayUnit string                  // every weekend day
+	weekdays       string                  // on weekdays
+	weekends       string                  // on weekends
+	on             string
+	in             string
+	until          string
+
+	// the phrase for a day of the month matching one of weekdays
+	ifWeekday func(weekdays string) string
+
+	// the phrase for the nth weekday of a month or year
+	nthWeekday func(n int, weekday string) string
+
+	weekdayNames [7]string
+	monthNames   [12]string
+
+	ordinalDay func(n int) string // the 1st, the last day...
+	list       func(items []string, conj string) string
+	and, or    string
+	times      func(count uint64) string
+	date       func(t time.Time) string
+}
+
+var english = phrasebook{
+	every: "every",
+	units: map[Frequency][2]string{
+		Yearly:   {"year", "years"},
+		Monthly:  {"month", "months"},
+		Weekly:   {"week", "weeks"},
+		Daily:    {"day", "days"},
+		Hourly:   {"hour", "hours"},
+		Minutely: {"minute", "minutes"},
+		Secondly: {"second", "seconds"},
+	},
+	weekdayUnit:    "weekday",
+	weekendDayUnit: "weekend day",
+	weekdays:       "weekdays",
+	weekends:       "weekends",
+	on:             "on",
+	in:             "in",
+	until:          "until",
+
+	ifWeekday: func(weekdays string) string { return "if it's a " + weekdays },
+	nthWeekday: func(n int, weekday string) string {
+		return fmt.Sprintf("the %s %s", englishOrdinalWord(n), weekday)
+	},
+
+	weekdayNames: [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	monthNames:   [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+
+	ordinalDay: func(n int) string {
+		switch {
+		case n == -1:
+			return "the last day"
+		case n < 0:
+			return fmt.Sprintf("the %s to last day", englishOrdinalWord(-n))
+		}
+		return "the " + ordinal(n)
+	},
+	list: func(items []string, conj string) string { return joinConj(items, ", ", conj) },
+	and:  "and",
+	or:   "or",
+	times: func(count uint64) string {
+		switch count {
+		case 1:
+			return "once"
+		case 2:
+			return "twice"
+		}
+		return fmt.Sprintf("%d times", count)
+	},
+	date: func(t time.Time) string {
+		if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+			return t.Format("Jan 2, 2006")
+		}
+		return t.Format("Jan 2, 2006 3:04pm")
+	},
+}
+
+func englishOrdinalWord(n int) string {
+	words := []string{"first", "second", "third", "fourth", "fifth"}
+	switch {
+	case n == -1:
+		return "last"
+	case n < 0:
+		return englishOrdinalWord(-n) + " to last"
+	case n <= len(words):
+		return words[n-1]
+	}
+	return ordinal(n)
+}
+
+func (rrule RRule) text(p phrasebook) (string, error) {
+	switch {
+	case len(rrule.BySetPos) > 0:
+		return "", errors.New("BYSETPOS cannot be described as text")
+	case len(rrule.ByYearDays) > 0:
+		return "", errors.New("BYYEARDAY cannot be described as text")
+	case len(rrule.ByWeekNumbers) > 0:
+		return "", errors.New("BYWEEKNO cannot be described as text")
+	case len(rrule.ByHours) > 0 || len(rrule.ByMinutes) > 0 || len(rrule.BySeconds) > 0:
+		return "", errors.New("BYHOUR, BYMINUTE, and BYSECOND cannot be described as text")
+	case len(rrule.ByMonthDays) > 0 && hasNthWeekday(rrule.ByWeekdays):
+		return "", errors.New("BYDAY with a numeric component cannot be described as text alongside BYMONTHDAY")
+	case rrule.weekStart() != DefaultWeekStart && rrule.Frequency == Weekly && rrule.Interval > 1 && len(rrule.ByWeekdays) > 1:
+		return "", errors.New("WKST cannot be described as text")
+	}
+	if _, ok := p.units[rrule.Frequency]; !ok {
+		return "", fmt.Errorf("invalid frequency %v", rrule.Frequency)
+	}
+
+	words := []string{p.every}
+
+	shortcut := ""
+	switch weekdayShortcut(rrule) {
+	case "weekday":
+		shortcut = p.weekdayUnit
+	case "weekend day":
+		shortcut = p.weekendDayUnit
+	}
+
+	if shortcut != "" {
+		words = append(words, shortcut)
+	} else if rrule.Interval > 1 {
+		words = append(words, fmt.Sprintf("%d %s", rrule.Interval, p.units[rrule.Frequency][1]))
+	} else {
+		words = append(words, p.units[rrule.Frequency][0])
+	}
+
+	if len(rrule.ByMonths) > 0 {
+		months := make([]string, len(rrule.ByMonths))
+		for i, m := range rrule.ByMonths {
+			months[i] = p.monthNames[m-1]
+		}
+		words = append(words, p.in, p.list(months, p.and))
+	}
+
+	switch {
+	case shortcut != "":
+	case len(rrule.ByMonthDays) > 0:
+		days := make([]string, len(rrule.ByMonthDays))
+		for i, d := range rrule.ByMonthDays {
+			days[i] = p.ordinalDay(d)
+		}
+		words = append(words, p.on, p.list(days, p.and))
+
+		// see note 2 on page 44 of RFC 5545: BYDAY limits BYMONTHDAY
+		if len(rrule.ByWeekdays) > 0 {
+			words = append(words, p.ifWeekday(p.list(rrule.weekdayNames(p), p.or)))
+		}
+	case len(rrule.ByWeekdays) > 0:
+		words = append(words, p.on)
+		switch {
+		case isWeekdaySet(rrule.ByWeekdays, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday):
+			words = append(words, p.weekdays)
+		case isWeekdaySet(rrule.ByWeekdays, time.Saturday, time.Sunday):
+			words = append(words, p.weekends)
+		default:
+			words = append(words, p.list(rrule.weekdayNames(p), p.and))
+		}
+	}
+
+	if !rrule.Until.IsZero() {
+		until := rrule.Until
+		if !rrule.UntilFloating {
+			until = until.In(rrule.Location())
+		}
+		words = append(words, p.until, p.date(until))
+	}
+
+	text := strings.Join(words, " ")
+	if rrule.Count > 0 {
+		text += ", " + p.times(rrule.Count)
+	}
+
+	return text, nil
+}
+
+// weekdayNames returns the name of each distinct BYDAY entry, such as
+// "Monday" or "the last Friday".
+func (rrule RRule) weekdayNames(p phrasebook) []string {
+	names := make([]string, 0, len(rrule.ByWeekdays))
+	seen := map[QualifiedWeekday]bool{}
+	for _, wd := range rrule.ByWeekdays {
+		if seen[wd] {
+			continue
+		}
+		seen[wd] = true
+
+		name := p.weekdayNames[wd.WD]
+		if wd.N != 0 {
+			name = p.nthWeekday(wd.N, name)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func hasNthWeekday(weekdays []QualifiedWeekday) bool {
+	for _, wd := range weekdays {
+		if wd.N != 0 {
+			return true
+		}
+	}
+	return false
+}
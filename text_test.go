@@ -0,0 +1,58 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestText(t *testing.T) {
+	cases := []struct {
+		RRule string
+		Text  string
+	}{
+		{"FREQ=DAILY", "every day"},
+		{"FREQ=DAILY;COUNT=1", "every day, once"},
+		{"FREQ=HOURLY;INTERVAL=6;COUNT=10", "every 6 hours, 10 times"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;UNTIL=20181231T000000Z", "every 2 weeks on Tuesday and Thursday until Dec 31, 2018"},
+		{"FREQ=WEEKLY;UNTIL=20181231T170000Z", "every week until Dec 31, 2018 5:00pm"},
+		{"FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", "every weekday"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=SA,SU", "every 2 weeks on weekends"},
+		{"FREQ=MONTHLY;BYDAY=1MO", "every month on the first Monday"},
+		{"FREQ=MONTHLY;BYDAY=-1FR,-2FR", "every month on the last Friday and the second to last Friday"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,15,-1", "every month on the 1st, the 15th, and the last day"},
+		{"FREQ=MONTHLY;BYMONTHDAY=11,-3", "every month on the 11th and the third to last day"},
+		{"FREQ=MONTHLY;BYMONTHDAY=13;BYDAY=FR", "every month on the 13th if it's a Friday"},
+		{"FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", "every year in November on the fourth Thursday"},
+		{"FREQ=YEARLY;BYMONTH=1,7;BYMONTHDAY=1", "every year in January and July on the 1st"},
+		{"FREQ=DAILY;BYMONTH=12;BYDAY=MO,TU,WE,TH,FR", "every weekday in December"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO;WKST=SU", "every 2 weeks on Monday"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.RRule, func(t *testing.T) {
+			rrule := MustRRule(tc.RRule)
+			rrule.Dtstart = time.Date(2018, 8, 25, 9, 0, 0, 0, time.UTC)
+
+			text, err := rrule.Text()
+			require.NoError(t, err)
+			assert.Equal(t, tc.Text, text)
+		})
+	}
+}
+
+func TestTextUndescribable(t *testing.T) {
+	for _, str := range []string{
+		"FREQ=MONTHLY;BYDAY=MO;BYSETPOS=-1",
+		"FREQ=YEARLY;BYYEARDAY=100",
+		"FREQ=YEARLY;BYWEEKNO=20",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=MONTHLY;BYMONTHDAY=1;BYDAY=1MO",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,MO;WKST=SU",
+	} {
+		_, err := MustRRule(str).Text()
+		assert.Error(t, err, str)
+	}
+}
@@ -3,7 +3,7 @@ package rrule
 import (
 	"errors"
 	"fmt"
-	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -54,6 +54,14 @@ func parseTime(str string, defaultLoc *time.Location) (time.Time, bool, error) {
 		t, err = time.ParseInLocation(rfc5545WithoutOffset, str, loc)
 	}
 
+	// a numeric offset, as in 20180825T090807+0200, is fixed. ParseInLocation
+	// would use loc instead if its offset happens to match, which would then
+	// follow loc's daylight savings transitions.
+	if offsetFound && !strings.HasSuffix(str, "Z") {
+		_, offset := t.Zone()
+		t = t.In(time.FixedZone("", offset))
+	}
+
 	// From RFC 5545:
 	//
 	//     If, based on the definition of the referenced time zone, the local
@@ -73,19 +81,23 @@ func parseTime(str string, defaultLoc *time.Location) (time.Time, bool, error) {
 	// transitions. (time.Date explicitly documents the same concept is
 	// undefined.) Therefore, here, we normalize according to the spec by
 	// trying to remove an hour and see if the local time is the same, and
-	// if so, we keep that difference. Otherwise, if the original string was
-	// in the 2am range, but the parsed time is less than 2 o'clock, advance an hour.
+	// if so, we keep that difference. Otherwise, if the local time doesn't
+	// exist, the parsed time has a different wall clock, earlier by the
+	// length of the gap, so advance by that much.
+	if err != nil {
+		return t, false, err
+	}
 	if tMinusHour := t.Add(-1 * time.Hour); t.Hour() == tMinusHour.Hour() {
 		t = tMinusHour
-	} else if twoAMRegex.MatchString(str) {
-		t = t.Add(1 * time.Hour)
+	} else if !offsetFound {
+		want, _ := time.Parse(rfc5545WithoutOffset, str)
+		wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+		t = t.Add(want.Sub(wall))
 	}
 
-	return t, !(tzidFound || offsetFound), err
+	return t, !(tzidFound || offsetFound), nil
 }
 
-var twoAMRegex = regexp.MustCompile("T02[0-9]{4}(Z|[0-9]{4})?$")
-
 func formatTime(prefix string, t time.Time, floatingLocation bool) string {
 	if floatingLocation {
 		return fmt.Sprintf("%s:%s", prefix, t.Format(rfc5545WithoutOffset))
@@ -95,5 +107,216 @@ func formatTime(prefix string, t time.Time, floatingLocation bool) string {
 		return fmt.Sprintf("%s:%sZ", prefix, t.Format(rfc5545WithoutOffset))
 	}
 
+	// unnamed zones, such as those parsed from a numeric offset, can't be
+	// referenced by TZID, so the time is written in UTC instead.
+	if t.Location().String() == "" {
+		return fmt.Sprintf("%s:%sZ", prefix, t.UTC().Format(rfc5545WithoutOffset))
+	}
+
 	return fmt.Sprintf("%s;TZID=%s:%s", prefix, t.Location(), t.Format(rfc5545WithoutOffset))
 }
+
+// parsePeriod parses a content line with a PERIOD value, such as
+// "RDATE;VALUE=PERIOD:19970101T180000Z/PT5H30M". The period ends either at a
+// date-time or after a duration.
+func parsePeriod(line string, defaultLoc *time.Location) (Period, error) {
+	value := propertyValue(line)
+	slashIdx := strings.Index(value, "/")
+	if slashIdx < 0 {
+		return Period{}, fmt.Errorf("period %q has no end", value)
+	}
+
+	// parseTime understands only the TZID parameter
+	params := "PERIOD:"
+	if tzid := propertyParam(line, "TZID"); tzid != "" {
+		params = "PERIOD;TZID=" + tzid + ":"
+	}
+	start, _, err := parseTime(params+value[:slashIdx], defaultLoc)
+	if err != nil {
+		return Period{}, err
+	}
+
+	end := value[slashIdx+1:]
+	if strings.HasPrefix(strings.TrimLeft(end, "+-"), "P") {
+		days, d, err := parseDuration(end)
+		if err != nil {
+			return Period{}, err
+		}
+		if days < 0 || d < 0 {
+			return Period{}, fmt.Errorf("period %q ends before it starts", value)
+		}
+		return Period{Start: start, End: start.AddDate(0, 0, days).Add(d)}, nil
+	}
+
+	t, _, err := parseTime(params+end, defaultLoc)
+	if err != nil {
+		return Period{}, err
+	}
+	if t.Before(start) {
+		return Period{}, fmt.Errorf("period %q ends before it starts", value)
+	}
+	return Period{Start: start, End: t}, nil
+}
+
+// formatPeriod formats a PERIOD value with an explicit end, which is written
+// in the location of the start.
+func formatPeriod(prefix string, p Period, floatingLocation bool) string {
+	end := formatTime(prefix, p.End.In(p.Start.Location()), floatingLocation)
+	return formatTime(prefix+";VALUE=PERIOD", p.Start, floatingLocation) + "/" + propertyValue(end)
+}
+
+// parseDuration parses a DURATION value, such as PT1H30M, P2D, or -P1W. Days
+// and weeks are nominal, as RFC 5545 specifies, so they're returned as a
+// number of calendar days, with weeks as 7 days, apart from the exact hours,
+// minutes, and seconds of the returned time.Duration.
+func parseDuration(str string) (int, time.Duration, error) {
+	s := str
+	sign := 1
+	if strings.HasPrefix(s, "-") {
+		sign = -1
+		s = s[1:]
+	} else if strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+
+	if !strings.HasPrefix(s, "P") || len(s) == 1 {
+		return 0, 0, fmt.Errorf("invalid duration %q", str)
+	}
+	s = s[1:]
+
+	var days int
+	var d time.Duration
+	inTime := false
+	for len(s) > 0 {
+		if s[0] == 'T' {
+			if inTime || len(s) == 1 {
+				return 0, 0, fmt.Errorf("invalid duration %q", str)
+			}
+			inTime = true
+			s = s[1:]
+			continue
+		}
+
+		digits := 0
+		for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+			digits++
+		}
+		if digits == 0 || digits == len(s) {
+			return 0, 0, fmt.Errorf("invalid duration %q", str)
+		}
+		n, err := strconv.Atoi(s[:digits])
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid duration %q", str)
+		}
+
+		var unit time.Duration
+		switch designator := s[digits]; {
+		case !inTime && designator == 'W':
+			days += 7 * n
+		case !inTime && designator == 'D':
+			days += n
+		case inTime && designator == 'H':
+			unit = time.Hour
+		case inTime && designator == 'M':
+			unit = time.Minute
+		case inTime && designator == 'S':
+			unit = time.Second
+		default:
+			return 0, 0, fmt.Errorf("invalid duration %q", str)
+		}
+		d += time.Duration(n) * unit
+		s = s[digits+1:]
+	}
+
+	return sign * days, time.Duration(sign) * d, nil
+}
+
+// formatDuration formats a number of nominal days and an exact d, which
+// should have the same sign, as a DURATION value, such as P1DT1H30M, in whole
+// seconds. d is written in hours however long it is, so it's parsed back as
+// exact.
+func formatDuration(days int, d time.Duration) string {
+	str := "P"
+	if days < 0 || d < 0 {
+		str = "-P"
+		days, d = -days, -d
+	}
+
+	h, m, sec := d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second
+
+	if days > 0 {
+		str += fmt.Sprintf("%dD", days)
+	}
+	if h == 0 && m == 0 && sec == 0 {
+		if days > 0 {
+			return str
+		}
+		return str + "T0S"
+	}
+
+	str += "T"
+	if h > 0 {
+		str += fmt.Sprintf("%dH", h)
+	}
+	if m > 0 {
+		str += fmt.Sprintf("%dM", m)
+	}
+	if sec > 0 {
+		str += fmt.Sprintf("%dS", sec)
+	}
+	return str
+}
+
+const rfc5545Date = "20060102"
+
+// date is a calendar day, independent of time of day or location.
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func dateOf(t time.Time) date {
+	y, m, d := t.Date()
+	return date{year: y, month: m, day: d}
+}
+
+// parseDate parses a DATE value, such as 20180825, as midnight in loc.
+func parseDate(str string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return time.ParseInLocation(rfc5545Date, str, loc)
+}
+
+func formatDate(prefix string, t time.Time) string {
+	return fmt.Sprintf("%s;VALUE=DATE:%s", prefix, t.Format(rfc5545Date))
+}
+
+// floating returns the time with t's date and wall clock in UTC, where every
+// day is 24 hours long, so that date arithmetic never crosses a daylight
+// saving transition.
+func floating(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// unfloat returns the time with the date and wall clock of the floating time
+// t in loc. If that wall clock doesn't exist in loc, gap decides the result,
+// and false is returned if the instance is skipped. Unlike time.Date, the
+// result doesn't depend on which side of the gap time.Date happens to choose.
+func unfloat(t time.Time, loc *time.Location, gap GapBehavior) (time.Time, bool) {
+	u := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+	skipped := t.Sub(floating(u))
+	if skipped == 0 {
+		return u, true
+	}
+	if gap == SkipGap {
+		return time.Time{}, false
+	}
+	// the wall clock came out earlier than asked for when time.Date used the
+	// offset after the gap; the offset before it gives the later instant.
+	if skipped > 0 {
+		u = u.Add(skipped)
+	}
+	return u, true
+}
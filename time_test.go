@@ -39,6 +39,18 @@ func TestParseTime(t *testing.T) {
 			ExpectedFloating: false,
 		},
 
+		{
+			Input:            "DTSTART:20180825T090807+0200",
+			Expected:         time.Date(2018, time.August, 25, 7, 8, 7, 0, time.UTC),
+			ExpectedFloating: false,
+		},
+		{
+			Input:            "DTSTART:20180825T090807-0530",
+			DefaultLoc:       NewYork(),
+			Expected:         time.Date(2018, time.August, 25, 14, 38, 7, 0, time.UTC),
+			ExpectedFloating: false,
+		},
+
 		{
 			Input:            "DTSTART;TZID=America/New_York:20181027T183615",
 			Expected:         time.Date(2018, time.October, 27, 18, 36, 15, 00, NewYork()),
@@ -59,6 +71,17 @@ func TestParseTime(t *testing.T) {
 			Expected:         time.Date(2007, time.March, 11, 3, 30, 0, 0, NewYork()),
 			ExpectedFloating: false,
 		},
+		{
+			Input:            "EXDATE;TZID=America/New_York:20180325T020000",
+			DefaultLoc:       Phoenix(),
+			Expected:         time.Date(2018, time.March, 25, 6, 0, 0, 0, time.UTC),
+			ExpectedFloating: false,
+		},
+		{
+			Input:            "DTSTART:20180325T020000Z",
+			Expected:         time.Date(2018, time.March, 25, 2, 0, 0, 0, time.UTC),
+			ExpectedFloating: false,
+		},
 	}
 
 	for _, tc := range cases {
@@ -70,3 +93,86 @@ func TestParseTime(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTimeNumericOffset(t *testing.T) {
+	// the offset matches New York's in August, but the zone stays fixed
+	got, _, err := parseTime("DTSTART:20180825T090807-0400", NewYork())
+	require.NoError(t, err)
+	_, offset := got.AddDate(0, 6, 0).Zone()
+	assert.Equal(t, -4*60*60, offset)
+
+	r, err := ParseRecurrence([]byte("DTSTART:20180825T090807+0200\nRRULE:FREQ=DAILY;COUNT=2"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2018-08-25T09:08:07+02:00", "2018-08-26T09:08:07+02:00"}, rfcAll(All(r.Iterator(), 0)))
+	assert.Equal(t, "DTSTART:20180825T070807Z\nRRULE:FREQ=DAILY;COUNT=2\n", r.String())
+}
+
+func TestParseRecurrenceDateTZID(t *testing.T) {
+	src := "DTSTART;TZID=America/New_York:20180324T020000\n" +
+		"RRULE:FREQ=DAILY;COUNT=3\n" +
+		"EXDATE;TZID=America/New_York:20180325T020000\n" +
+		"RDATE;TZID=America/Phoenix:20180330T120000\n"
+
+	r, err := ParseRecurrence([]byte(src), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, NewYork().String(), r.ExDates[0].Location().String())
+	assert.Equal(t, Phoenix().String(), r.RDates[0].Location().String())
+	assert.Equal(t, []string{"2018-03-24T06:00:00Z", "2018-03-26T06:00:00Z", "2018-03-30T19:00:00Z"}, rfcAll(utcAll(All(r.Iterator(), 0))))
+
+	// without TZID, loc is used
+	r, err = ParseRecurrence([]byte("DTSTART:20180324T020000\nRDATE:20180330T120000"), Phoenix())
+	require.NoError(t, err)
+	assert.Equal(t, Phoenix().String(), r.RDates[0].Location().String())
+}
+
+func utcAll(tt []time.Time) []time.Time {
+	for i, t := range tt {
+		tt[i] = t.UTC()
+	}
+	return tt
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]struct {
+		Days     int
+		Duration time.Duration
+	}{
+		"PT0S":         {0, 0},
+		"PT1H30M":      {0, 90 * time.Minute},
+		"P2D":          {2, 0},
+		"P1DT12H":      {1, 12 * time.Hour},
+		"P1W":          {7, 0},
+		"-P1DT15M":     {-1, -15 * time.Minute},
+		"+PT10S":       {0, 10 * time.Second},
+		"P15DT5H0M20S": {15, 5*time.Hour + 20*time.Second},
+	}
+	for str, want := range cases {
+		days, d, err := parseDuration(str)
+		require.NoError(t, err, str)
+		assert.Equal(t, want.Days, days, str)
+		assert.Equal(t, want.Duration, d, str)
+	}
+
+	for _, str := range []string{"", "P", "PT", "1H", "P1H", "PT1D", "P1DT", "PTH", "P1D1"} {
+		_, _, err := parseDuration(str)
+		assert.Error(t, err, str)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	cases := map[string]struct {
+		Days     int
+		Duration time.Duration
+	}{
+		"PT0S":       {0, 0},
+		"PT1H30M":    {0, 90 * time.Minute},
+		"P2D":        {2, 0},
+		"P1DT12H":    {1, 12 * time.Hour},
+		"PT36H":      {0, 36 * time.Hour},
+		"-PT15M":     {0, -15 * time.Minute},
+		"P15DT5H20S": {15, 5*time.Hour + 20*time.Second},
+	}
+	for str, tc := range cases {
+		assert.Equal(t, str, formatDuration(tc.Days, tc.Duration))
+	}
+}
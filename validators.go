@@ -21,7 +21,7 @@ func validSecond(seconds []int) validFunc {
 		if t == nil {
 			return false
 		}
-		return m[t.Second()]
+		return m[t.Second()] || m[t.Second()-60]
 	}
 }
 
@@ -36,7 +36,7 @@ func validMinute(minutes []int) validFunc {
 		if t == nil {
 			return false
 		}
-		return m[t.Minute()]
+		return m[t.Minute()] || m[t.Minute()-60]
 	}
 }
 
@@ -51,7 +51,7 @@ func validHour(hours []int) validFunc {
 		if t == nil {
 			return false
 		}
-		return m[t.Hour()]
+		return m[t.Hour()] || m[t.Hour()-24]
 	}
 }
 
@@ -82,11 +82,11 @@ func validMonthDay(monthdays []int) validFunc {
 		if t == nil {
 			return false
 		}
-		return m[t.Day()]
+		return m[t.Day()] || m[t.Day()-daysInMonth(*t)-1]
 	}
 }
 
-func validWeek(weeks []int) validFunc {
+func validWeek(weeks []int, weekStarts time.Weekday) validFunc {
 	if len(weeks) == 0 {
 		return alwaysValid
 	}
@@ -97,7 +97,8 @@ func validWeek(weeks []int) validFunc {
 		if t == nil {
 			return false
 		}
-		return m[1+t.YearDay()/7]
+		week, n := weekNumber(*t, weekStarts)
+		return m[week] || m[week-n-1]
 	}
 }
 
@@ -127,6 +128,6 @@ func validYearDay(yeardays []int) validFunc {
 		if t == nil {
 			return false
 		}
-		return m[t.YearDay()]
+		return m[t.YearDay()] || m[t.YearDay()-daysInYear(*t)-1]
 	}
 }
@@ -46,7 +46,7 @@ func WeekdayString(wd time.Weekday) string {
 	return wdStr
 }
 
-func weekdaysInYear(t time.Time, wd QualifiedWeekday, ib invalidBehavior) []time.Time {
+func weekdaysInYear(t time.Time, wd QualifiedWeekday, ib InvalidBehavior) []time.Time {
 	allWDs := make([]time.Time, 0, 5)
 
 	// start on first of year
@@ -75,12 +75,12 @@ func weekdaysInYear(t time.Time, wd QualifiedWeekday, ib invalidBehavior) []time
 		// positive index specified. count to the correct instance
 		if wd.N > len(allWDs) {
 			switch ib {
-			case omitInvalid:
+			case OmitInvalid:
 				return nil
-			case prevInvalid:
+			case PrevInvalid:
 				idx := len(allWDs) - 1
 				return allWDs[idx:idx]
-			case nextInvalid:
+			case NextInvalid:
 				return []time.Time{allWDs[len(allWDs)-1].AddDate(0, 0, 7)}
 			}
 		}
@@ -102,11 +102,11 @@ func weekdaysInYear(t time.Time, wd QualifiedWeekday, ib invalidBehavior) []time
 
 	if idx < 0 || idx > len(allWDs) {
 		switch ib {
-		case omitInvalid:
+		case OmitInvalid:
 			return nil
-		case prevInvalid:
+		case PrevInvalid:
 			return []time.Time{allWDs[0].AddDate(0, 0, -7)}
-		case nextInvalid:
+		case NextInvalid:
 			return allWDs[0:0]
 		}
 	}
@@ -115,15 +115,9 @@ func weekdaysInYear(t time.Time, wd QualifiedWeekday, ib invalidBehavior) []time
 }
 
 func backToWeekday(t time.Time, day time.Weekday) time.Time {
-	for t.Weekday() != day {
-		t = t.AddDate(0, 0, -1)
-	}
-	return t
+	return t.AddDate(0, 0, -daysFrom(t.Weekday(), day))
 }
 
 func forwardToWeekday(t time.Time, day time.Weekday) time.Time {
-	for t.Weekday() != day {
-		t = t.AddDate(0, 0, 1)
-	}
-	return t
+	return t.AddDate(0, 0, daysTil(t.Weekday(), day))
 }
@@ -12,7 +12,7 @@ import (
 // list, outweighs the concerns.
 //
 // weekdays must have at least one element
-func weekdaysInMonth(t time.Time, weekdays []QualifiedWeekday, bySetPos []int, ib invalidBehavior) []time.Time {
+func weekdaysInMonth(t time.Time, weekdays []QualifiedWeekday, ib InvalidBehavior) []time.Time {
 	firstDay := firstOfMonth(t)
 	firstWeekday := firstDay.Weekday()
 	lastDay := lastOfMonth(t)
@@ -50,7 +50,6 @@ func weekdaysInMonth(t time.Time, weekdays []QualifiedWeekday, bySetPos []int, i
 	}
 
 	sort.Ints(dates)
-	dates = limitInstancesBySetPos(dates, bySetPos)
 
 	out := make([]time.Time, len(dates))
 	for i, date := range dates {